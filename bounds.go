@@ -0,0 +1,659 @@
+package shapes
+
+import (
+	"image"
+	"math"
+)
+
+// rectBounds returns the smallest image.Rectangle containing the given float area.
+func rectBounds(minX, minY, maxX, maxY float32) image.Rectangle {
+	return image.Rect(
+		int(math.Floor(float64(minX))), int(math.Floor(float64(minY))),
+		int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY))),
+	)
+}
+
+// pointsBounds returns the smallest image.Rectangle containing all the given points.
+func pointsBounds(pts []PointF32) image.Rectangle {
+	if len(pts) == 0 {
+		return image.Rectangle{}
+	}
+	minX, minY := pts[0].X, pts[0].Y
+	maxX, maxY := minX, minY
+	for _, pt := range pts[1:] {
+		minX, minY = min(minX, pt.X), min(minY, pt.Y)
+		maxX, maxY = max(maxX, pt.X), max(maxY, pt.Y)
+	}
+	return rectBounds(minX, minY, maxX, maxY)
+}
+
+// AreaBounds returns the area that [Renderer.DrawArea]() would touch for the given
+// parameters, relative to the target's origin. Like the rest of bounds functions,
+// the current rounding and pixel-perfect modes are taken into account.
+func (r *Renderer) AreaBounds(ox, oy, w, h, rounding float32) image.Rectangle {
+	if w < 0 {
+		w = -w
+		ox -= w
+	}
+	if h < 0 {
+		h = -h
+		oy -= h
+	}
+	ox, oy, w, h, rounding = r.snapArea(ox, oy, w, h, rounding)
+	_, outRounding := r.splitRounding(rounding)
+	if outRounding > 0 {
+		ox, oy = ox-outRounding, oy-outRounding
+		w, h = w+outRounding*2, h+outRounding*2
+	}
+	return rectBounds(ox, oy, ox+w, oy+h)
+}
+
+// StrokeAreaBounds returns the area that [Renderer.StrokeArea]() would touch.
+func (r *Renderer) StrokeAreaBounds(ox, oy, w, h, outThickness, inThickness, rounding float32) image.Rectangle {
+	if w < 0 {
+		w = -w
+		ox -= w
+	}
+	if h < 0 {
+		h = -h
+		oy -= h
+	}
+	if outThickness == 0 && inThickness == 0 {
+		return image.Rectangle{}
+	}
+	_, outRounding := r.splitRounding(rounding)
+	if outRounding > 0 {
+		ox, oy = ox-outRounding, oy-outRounding
+		w, h = w+outRounding*2, h+outRounding*2
+	}
+	return rectBounds(ox-outThickness, oy-outThickness, ox+w+outThickness, oy+h+outThickness)
+}
+
+// LineBounds returns the area that [Renderer.DrawLine]() would touch.
+func (r *Renderer) LineBounds(ox, oy, fx, fy, thickness float64) image.Rectangle {
+	ox, oy, fx, fy, thickness = r.snapLine(ox, oy, fx, fy, thickness)
+	corners := lineCorners(ox, oy, fx, fy, thickness)
+	return pointsBounds(corners[:])
+}
+
+// TaperedLineBounds returns the area that [Renderer.DrawTaperedLine]() would touch.
+func (r *Renderer) TaperedLineBounds(ox, oy, fx, fy, startThick, endThick float64) image.Rectangle {
+	if startThick < 0 || endThick < 0 || (startThick == 0 && endThick == 0) {
+		return image.Rectangle{}
+	}
+	outline := taperedLineOutline(ox, oy, fx, fy, startThick, endThick)
+	return pointsBounds(outline[:])
+}
+
+// CircleBounds returns the area that [Renderer.DrawCircle]() would touch.
+func (r *Renderer) CircleBounds(cx, cy, radius float32) image.Rectangle {
+	cx, cy = r.snapCenter(cx, cy)
+	radius = r.snapRadius(radius)
+	return rectBounds(cx-radius, cy-radius, cx+radius, cy+radius)
+}
+
+// StrokeCircleBounds returns the area that [Renderer.StrokeCircle]() would touch.
+func (r *Renderer) StrokeCircleBounds(cx, cy, radius, thickness float32) image.Rectangle {
+	if thickness <= 0 {
+		return image.Rectangle{}
+	}
+	if radius <= thickness/2.0 {
+		rem := thickness/2.0 - radius
+		if rem > 0 {
+			return r.CircleBounds(cx, cy, rem)
+		}
+		return image.Rectangle{}
+	}
+	ext := radius + ceilF32(thickness/2.0)
+	return rectBounds(cx-ext, cy-ext, cx+ext, cy+ext)
+}
+
+// RingBounds returns the area that [Renderer.DrawRing]() would touch.
+func (r *Renderer) RingBounds(cx, cy, inRadius, outRadius float32) image.Rectangle {
+	if inRadius >= outRadius {
+		return image.Rectangle{}
+	}
+	return r.CircleBounds(cx, cy, outRadius)
+}
+
+// RingSectorBounds returns the area that [Renderer.DrawRingSector]() would touch.
+func (r *Renderer) RingSectorBounds(cx, cy, inRadius, outRadius float32, startRads, endRads float64, rounding float32) image.Rectangle {
+	return r.ringSectorBounds(cx, cy, inRadius, outRadius, 0, startRads, endRads, rounding)
+}
+
+// StrokeRingSectorBounds returns the area that [Renderer.StrokeRingSector]() would touch.
+func (r *Renderer) StrokeRingSectorBounds(cx, cy, inRadius, outRadius, thickness float32, startRads, endRads float64, rounding float32) image.Rectangle {
+	if thickness <= 0 {
+		return image.Rectangle{}
+	}
+	return r.ringSectorBounds(cx, cy, inRadius, outRadius, thickness, startRads, endRads, rounding)
+}
+
+// ringSectorBounds mirrors the cases of DrawRingSector (thickness == 0) and StrokeRingSector.
+func (r *Renderer) ringSectorBounds(cx, cy, inRadius, outRadius, thickness float32, startRads, endRads float64, rounding float32) image.Rectangle {
+	if inRadius >= outRadius || outRadius < 0 || startRads == endRads {
+		return image.Rectangle{}
+	}
+
+	if inRadius <= 0 {
+		if thickness == 0 {
+			return r.PieBounds(cx, cy, outRadius, startRads, endRads, rounding)
+		}
+		return r.StrokePieBounds(cx, cy, outRadius, thickness, startRads, endRads, rounding)
+	}
+	if endRads >= startRads+2*math.Pi {
+		if thickness == 0 {
+			return r.RingBounds(cx, cy, inRadius, outRadius)
+		}
+		return r.StrokeCircleBounds(cx, cy, outRadius, thickness) // contains the inner stroke
+	}
+
+	startRads, endRads = normURads(startRads), normURads(endRads)
+	minX, minY, maxX, maxY := ringSectorBounds(cx, cy, inRadius, outRadius, startRads, endRads)
+	margin := max(rounding, 0) + thickness/2.0
+	return rectBounds(minX-margin, minY-margin, maxX+margin, maxY+margin)
+}
+
+// PieBounds returns the area that [Renderer.DrawPie]() would touch.
+func (r *Renderer) PieBounds(cx, cy, radius float32, startRads, endRads float64, rounding float32) image.Rectangle {
+	if startRads == endRads || radius < 0 {
+		return image.Rectangle{}
+	}
+	if endRads >= startRads+2*math.Pi {
+		return r.CircleBounds(cx, cy, radius)
+	}
+	startRads, endRads = normURads(startRads), normURads(endRads)
+	rate := float64(uradsDeltaCW(startRads, endRads)) / (2 * math.Pi)
+	return r.pieRateBounds(cx, cy, radius, 0, startRads, endRads, rate, rounding)
+}
+
+// PieRateBounds returns the area that [Renderer.DrawPieRate]() would touch.
+func (r *Renderer) PieRateBounds(cx, cy, radius float32, centerDir, rate float64, rounding float32) image.Rectangle {
+	if rate <= 0 || radius < 0 {
+		return image.Rectangle{}
+	}
+	if rate > 1.0 {
+		return r.CircleBounds(cx, cy, radius)
+	}
+	ratePi := rate * math.Pi
+	centerDir = normURads(centerDir)
+	startRads, endRads := uradsAddCW(centerDir, -ratePi), uradsAddCW(centerDir, ratePi)
+	return r.pieRateBounds(cx, cy, radius, 0, startRads, endRads, rate, rounding)
+}
+
+// StrokePieBounds returns the area that [Renderer.StrokePie]() would touch.
+func (r *Renderer) StrokePieBounds(cx, cy, radius, thickness float32, startRads, endRads float64, rounding float32) image.Rectangle {
+	if startRads == endRads || radius < 0 {
+		return image.Rectangle{}
+	}
+	if endRads >= startRads+2*math.Pi {
+		return r.StrokeCircleBounds(cx, cy, radius, thickness)
+	}
+	startRads, endRads = normURads(startRads), normURads(endRads)
+	rate := float64(uradsDeltaCW(startRads, endRads)) / (2 * math.Pi)
+	return r.pieRateBounds(cx, cy, radius, thickness, startRads, endRads, rate, rounding)
+}
+
+// StrokePieRateBounds returns the area that [Renderer.StrokePieRate]() would touch.
+func (r *Renderer) StrokePieRateBounds(cx, cy, radius, thickness float32, centerDir, rate float64, rounding float32) image.Rectangle {
+	if rate <= 0 || radius < 0 {
+		return image.Rectangle{}
+	}
+	if rate > 1.0 {
+		return r.StrokeCircleBounds(cx, cy, radius, thickness)
+	}
+	ratePi := rate * math.Pi
+	centerDir = normURads(centerDir)
+	startRads, endRads := uradsAddCW(centerDir, -ratePi), uradsAddCW(centerDir, ratePi)
+	return r.pieRateBounds(cx, cy, radius, thickness, startRads, endRads, rate, rounding)
+}
+
+// preconditions: same as internalDrawPieRate
+func (r *Renderer) pieRateBounds(cx, cy, radius, thickness float32, startRads, endRads, rate float64, rounding float32) image.Rectangle {
+	_, _, _, margin := r.pieRoundingParams(radius, rate, rounding)
+	margin += thickness
+	minX, minY, maxX, maxY := pieBounds(cx, cy, radius, startRads, endRads)
+	return rectBounds(minX-margin, minY-margin, maxX+margin, maxY+margin)
+}
+
+// EllipseBounds returns the area that [Renderer.DrawEllipse]() would touch.
+func (r *Renderer) EllipseBounds(cx, cy, horzRadius, vertRadius float32, rads float64) image.Rectangle {
+	cx, cy = r.snapCenter(cx, cy)
+	horzRadius, vertRadius = r.snapRadius(horzRadius), r.snapRadius(vertRadius)
+	halfWidth, halfHeight := ellipseHalfExtents(horzRadius, vertRadius, rads)
+	return rectBounds(cx-halfWidth, cy-halfHeight, cx+halfWidth, cy+halfHeight)
+}
+
+// TriangleBounds returns the area that [Renderer.DrawTriangle]() would touch.
+func (r *Renderer) TriangleBounds(ox1, oy1, ox2, oy2, ox3, oy3, rounding float64) image.Rectangle {
+	return r.StrokeTriangleBounds(ox1, oy1, ox2, oy2, ox3, oy3, 0.0, rounding)
+}
+
+// StrokeTriangleBounds returns the area that [Renderer.StrokeTriangle]() would touch.
+func (r *Renderer) StrokeTriangleBounds(ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding float64) image.Rectangle {
+	ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding = r.snapTriangle(ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding)
+	return r.triangleBounds(ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding)
+}
+
+// TriangleColorsBounds returns the area that [Renderer.DrawTriangleColors]() would touch.
+func (r *Renderer) TriangleColorsBounds(ox1, oy1, ox2, oy2, ox3, oy3, rounding float64) image.Rectangle {
+	return r.triangleBounds(ox1, oy1, ox2, oy2, ox3, oy3, 0.0, rounding)
+}
+
+// triangleBounds is the common part of the triangle bounds functions, after snapping.
+func (r *Renderer) triangleBounds(ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding float64) image.Rectangle {
+	area := math.Abs((ox1*(oy2-oy3) + ox2*(oy3-oy1) + ox3*(oy1-oy2)) / 2)
+	if area < 1e-6 {
+		return image.Rectangle{}
+	}
+
+	_, outRounding := r.splitRounding(float32(rounding))
+	minX, maxX := min(ox1, ox2, ox3), max(ox1, ox2, ox3)
+	minY, maxY := min(oy1, oy2, oy3), max(oy1, oy2, oy3)
+	margin := max(thickness/2.0, 0) + float64(outRounding)
+	return rectBounds(float32(minX-margin), float32(minY-margin), float32(maxX+margin), float32(maxY+margin))
+}
+
+// HexagonBounds returns the area that [Renderer.DrawHexagon]() would touch.
+func (r *Renderer) HexagonBounds(ox, oy, radius, rounding float32) image.Rectangle {
+	_, outRounding := r.splitRounding(rounding)
+	bounds := radius + outRounding
+	return rectBounds(ox-bounds, oy-bounds, ox+bounds, oy+bounds)
+}
+
+// QuadBounds returns the area that [Renderer.DrawQuad]() would touch.
+func (r *Renderer) QuadBounds(quad [4]PointF32, rounding float32) image.Rectangle {
+	geometry := quad
+	if rounding >= 0 {
+		geometry = expandQuad(quad, rounding)
+	}
+	return pointsBounds(geometry[:])
+}
+
+// QuadSoftBounds returns the area that [Renderer.DrawQuadSoft]() would touch.
+// The soft edge is applied within the quad, so this is the same as [Renderer.QuadBounds]().
+func (r *Renderer) QuadSoftBounds(quad [4]PointF32, rounding, softEdge float32) image.Rectangle {
+	return r.QuadBounds(quad, rounding)
+}
+
+// MetaballsBounds returns the area that [Renderer.DrawMetaballs]() would touch.
+func (r *Renderer) MetaballsBounds(centers []PointF32, radii []float32, threshold float32) image.Rectangle {
+	if len(centers) == 0 || len(centers) != len(radii) || threshold <= 0 {
+		return image.Rectangle{}
+	}
+	minX, minY, maxX, maxY, ok := metaballsRect(centers, radii, threshold)
+	if !ok {
+		return image.Rectangle{}
+	}
+	return rectBounds(minX, minY, maxX, maxY)
+}
+
+// RibbonBounds returns the area that [Renderer.DrawRibbon]() would touch.
+func (r *Renderer) RibbonBounds(pts []PointF32, widths []float32) image.Rectangle {
+	if len(pts) < 2 || len(widths) != len(pts) || len(pts) > MaxRibbonPoints {
+		return image.Rectangle{}
+	}
+	vertices := r.getAuxVertices(len(pts) * 2)
+	if !r.setRibbonGeometry(0, 0, vertices, pts, widths) {
+		return image.Rectangle{}
+	}
+	minX, minY := vertices[0].DstX, vertices[0].DstY
+	maxX, maxY := minX, minY
+	for _, v := range vertices[1:] {
+		minX, minY = min(minX, v.DstX), min(minY, v.DstY)
+		maxX, maxY = max(maxX, v.DstX), max(maxY, v.DstY)
+	}
+	return rectBounds(minX, minY, maxX, maxY)
+}
+
+// ParametricBounds returns the area that [Renderer.DrawParametric]() would touch.
+// Like the draw, this samples f, so it has a similar CPU cost.
+func (r *Renderer) ParametricBounds(f func(t float64) PointF32, t0, t1 float64, thickness float32) image.Rectangle {
+	if thickness <= 0 || t0 == t1 {
+		return image.Rectangle{}
+	}
+	r.setParametricPath(f, t0, t1, thickness)
+	return r.RibbonBounds(r.auxPoints, r.auxWidths)
+}
+
+// SDFBounds returns the area that [Renderer.DrawSDF]() would touch. Unlike
+// [SDF.Bounds](), this includes the antialiasing margin.
+func (r *Renderer) SDFBounds(sdf *SDF) image.Rectangle {
+	minX, minY, maxX, maxY, ok := sdf.drawRect()
+	if !ok {
+		return image.Rectangle{}
+	}
+	return rectBounds(minX, minY, maxX, maxY)
+}
+
+// MapQuad4Bounds returns the area that [Renderer.MapQuad4]() would touch.
+func (r *Renderer) MapQuad4Bounds(quad [4]PointF32) image.Rectangle {
+	return pointsBounds(quad[:])
+}
+
+// MapProjectiveBounds returns the area that [Renderer.MapProjective]() would touch.
+func (r *Renderer) MapProjectiveBounds(quad [4]PointF32) image.Rectangle {
+	return pointsBounds(quad[:])
+}
+
+// ExpansionBounds returns the area that [Renderer.ApplyExpansion]() would touch for
+// a mask of the given size, relative to the target's origin. Like the rest of effect
+// bounds functions, sides clamped with [Renderer.SetSourceClamping]() get no margins.
+func (r *Renderer) ExpansionBounds(maskWidth, maskHeight int, ox, oy, thickness float32) image.Rectangle {
+	left, top, right, bottom := r.clampMargins(thickness/2.0 + 1)
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, top, right, bottom)
+}
+
+// ExpansionRectBounds returns the area that [Renderer.ApplyExpansionRect]() would touch.
+func (r *Renderer) ExpansionRectBounds(maskWidth, maskHeight int, ox, oy, thickness float32) image.Rectangle {
+	left, top, right, bottom := r.clampMargins(ceilF32(thickness))
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, top, right, bottom)
+}
+
+// ErosionBounds returns the area that [Renderer.ApplyErosion]() would touch.
+func (r *Renderer) ErosionBounds(maskWidth, maskHeight int, ox, oy float32) image.Rectangle {
+	left, top, right, bottom := r.clampMargins(1)
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, top, right, bottom)
+}
+
+// OutlineBounds returns the area that [Renderer.ApplyOutline]() would touch.
+func (r *Renderer) OutlineBounds(maskWidth, maskHeight int, ox, oy, thickness float32) image.Rectangle {
+	return r.ExpansionBounds(maskWidth, maskHeight, ox, oy, thickness)
+}
+
+// BlurBounds returns the area that [Renderer.ApplyBlur]() would touch.
+func (r *Renderer) BlurBounds(maskWidth, maskHeight int, ox, oy, radius float32) image.Rectangle {
+	left, top, right, bottom := r.clampMargins(radius/2.0 + 1)
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, top, right, bottom)
+}
+
+// Blur2Bounds returns the area that [Renderer.ApplyBlur2]() would touch.
+func (r *Renderer) Blur2Bounds(maskWidth, maskHeight int, ox, oy, radius float32) image.Rectangle {
+	_, top, _, bottom := r.clampMargins(ceilF32(radius / 2.0))
+	tmpHeight := maskHeight + int(top+bottom)
+	return r.HorzBlurBounds(maskWidth, tmpHeight, ox, oy-top, radius)
+}
+
+// VertBlurBounds returns the area that [Renderer.ApplyVertBlur]() would touch.
+func (r *Renderer) VertBlurBounds(maskWidth, maskHeight int, ox, oy, radius float32) image.Rectangle {
+	_, top, _, bottom := r.clampMargins(ceilF32(radius / 2.0))
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, 0, top, 0, bottom)
+}
+
+// HorzBlurBounds returns the area that [Renderer.ApplyHorzBlur]() would touch.
+func (r *Renderer) HorzBlurBounds(maskWidth, maskHeight int, ox, oy, radius float32) image.Rectangle {
+	left, _, right, _ := r.clampMargins(ceilF32(radius / 2.0))
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, 0, right, 0)
+}
+
+// HardShadowBounds returns the area that [Renderer.ApplyHardShadow]() would touch.
+func (r *Renderer) HardShadowBounds(maskWidth, maskHeight int, ox, oy, xOffset, yOffset float32) image.Rectangle {
+	return r.ShadowBounds(maskWidth, maskHeight, ox, oy, xOffset, yOffset, 0, ClampNone)
+}
+
+// ShadowBounds returns the area that [Renderer.ApplyShadow]() would touch.
+func (r *Renderer) ShadowBounds(maskWidth, maskHeight int, ox, oy, xOffset, yOffset, radius float32, clamping Clamping) image.Rectangle {
+	clamping |= r.sourceClamping
+	hr32 := radius / 2.0
+	top, bottom, left, right := hr32, hr32, hr32, hr32
+	if clamping&ClampBottom != 0 {
+		bottom = 0
+	}
+	if clamping&ClampTop != 0 {
+		top = 0
+	}
+	if clamping&ClampLeft != 0 {
+		left = 0
+	}
+	if clamping&ClampRight != 0 {
+		right = 0
+	}
+	left, top = left-min(0, xOffset), top-min(0, yOffset)
+	right, bottom = right+max(0, xOffset), bottom+max(0, yOffset)
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, top, right, bottom)
+}
+
+// ZoomShadowBounds returns the area that [Renderer.ApplyZoomShadow]() would touch.
+func (r *Renderer) ZoomShadowBounds(maskWidth, maskHeight int, ox, oy, xOffset, yOffset, zoom float32, clamping Clamping) image.Rectangle {
+	clamping |= r.sourceClamping
+	top, left := float32(maskHeight)*0.5*(zoom-1.0), float32(maskWidth)*0.5*(zoom-1.0)
+	bottom, right := top, left
+	if clamping&ClampBottom != 0 {
+		bottom = 0
+	}
+	if clamping&ClampTop != 0 {
+		top = 0
+	}
+	if clamping&ClampLeft != 0 {
+		left = 0
+	}
+	if clamping&ClampRight != 0 {
+		right = 0
+	}
+	left, top = left-min(0, xOffset), top-min(0, yOffset)
+	right, bottom = right+max(0, xOffset), bottom+max(0, yOffset)
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, top, right, bottom)
+}
+
+// GlowBounds returns the area that [Renderer.ApplyGlow]() would touch. This is
+// also valid for [Renderer.ApplySimpleGlow]() with horzRadius = vertRadius = radius.
+func (r *Renderer) GlowBounds(maskWidth, maskHeight int, ox, oy, horzRadius, vertRadius float32) image.Rectangle {
+	_, top, _, bottom := r.clampMargins(vertRadius/2.0 + 1.0)
+	tmpHeight := int(math.Ceil(float64(float32(maskHeight) + top + bottom)))
+	return r.HorzBlurBounds(maskWidth, tmpHeight, ox, oy-top, horzRadius)
+}
+
+// HorzGlowBounds returns the area that [Renderer.ApplyHorzGlow]() and
+// [Renderer.ApplyDarkHorzGlow]() would touch.
+func (r *Renderer) HorzGlowBounds(maskWidth, maskHeight int, ox, oy, horzRadius float32) image.Rectangle {
+	left, _, right, _ := r.clampMargins(horzRadius/2.0 + 1.0)
+	return maskRectBounds(maskWidth, maskHeight, ox, oy, left, 0, right, 0)
+}
+
+// BlurD4Bounds returns the area that [Renderer.ApplyBlurD4]() would touch. This is
+// also valid for [Renderer.ApplyGlowD4]() and [Renderer.ApplyColorGlowD4]().
+func (r *Renderer) BlurD4Bounds(maskWidth, maskHeight int, ox, oy float32, horzKernel, vertKernel GaussKern) image.Rectangle {
+	// see applyKernelD4
+	const downscaling = 4
+	halfHorzMargin, halfVertMargin := float64(horzKernel.Radius()), float64(vertKernel.Radius())
+	dkernW64 := float64(maskWidth)/downscaling + halfHorzMargin*2.0
+	dkernH64 := float64(maskHeight)/downscaling + halfVertMargin*2.0
+	dkernImgWidth, dkernImgHeight := math.Ceil(dkernW64)+2, math.Ceil(dkernH64)+2
+	fx, fy := ox+float32(-downscaling-halfHorzMargin*downscaling), oy+float32(-downscaling-halfVertMargin*downscaling)
+	return rectBounds(fx, fy, fx+float32(dkernImgWidth)*downscaling, fy+float32(dkernImgHeight)*downscaling)
+}
+
+// MaskBounds returns the area that [Renderer.Mask]() would touch for a source of
+// the given size. This is also valid for [Renderer.MaskThreshold]().
+func (r *Renderer) MaskBounds(sourceWidth, sourceHeight int, ox, oy float32) image.Rectangle {
+	return maskRectBounds(sourceWidth, sourceHeight, ox, oy, 0, 0, 0, 0)
+}
+
+// MaskAtBounds returns the area that [Renderer.MaskAt]() would touch for a source of
+// the given size. Only the source position is taken into account, not the mask's.
+func (r *Renderer) MaskAtBounds(sourceWidth, sourceHeight int, ox, oy float32) image.Rectangle {
+	return maskRectBounds(sourceWidth, sourceHeight, ox, oy, 0, 0, 0, 0)
+}
+
+// MaskHorzBounds returns the area that [Renderer.MaskHorz]() would touch.
+func (r *Renderer) MaskHorzBounds(sourceWidth, sourceHeight int, x, y float32) image.Rectangle {
+	return maskRectBounds(sourceWidth, sourceHeight, x, y, 0, 0, 0, 0)
+}
+
+// MaskCircleBounds returns the area that [Renderer.MaskCircle]() would touch.
+func (r *Renderer) MaskCircleBounds(sourceWidth, sourceHeight int, cx, cy, srcOffsetX, srcOffsetY, hardRadius, softEdge float32) image.Rectangle {
+	if softEdge < 0 {
+		hardRadius += softEdge
+		softEdge = -softEdge
+	}
+	if hardRadius <= 0 && softEdge == 0 {
+		return image.Rectangle{}
+	}
+	ox, oy := maskCircleOrigin(float32(sourceWidth), float32(sourceHeight), cx, cy, srcOffsetX, srcOffsetY)
+	return maskRectBounds(sourceWidth, sourceHeight, ox, oy, 0, 0, 0, 0)
+}
+
+// JFMHeatBounds returns the area that [Renderer.JFMHeat]() would touch.
+func (r *Renderer) JFMHeatBounds(jfmapWidth, jfmapHeight int, ox, oy float32) image.Rectangle {
+	return maskRectBounds(jfmapWidth, jfmapHeight, ox, oy, 0, 0, 0, 0)
+}
+
+// JFMExpandBounds returns the area that [Renderer.JFMExpand]() would touch, which
+// is always the source area. This is also valid for [Renderer.JFMErode](). See
+// [Renderer.JFMExpandMargins]() for the padding needed within the source.
+func (r *Renderer) JFMExpandBounds(sourceWidth, sourceHeight int, ox, oy float32) image.Rectangle {
+	return maskRectBounds(sourceWidth, sourceHeight, ox, oy, 0, 0, 0, 0)
+}
+
+// JFMOutlineBounds returns the area that [Renderer.JFMOutline]() would touch. Like
+// the rest of jumping flood operations, outlines are limited to the source area.
+func (r *Renderer) JFMOutlineBounds(sourceWidth, sourceHeight int, ox, oy float32) image.Rectangle {
+	return maskRectBounds(sourceWidth, sourceHeight, ox, oy, 0, 0, 0, 0)
+}
+
+// WarpBarrelBounds returns the area that [Renderer.WarpBarrel]() would touch,
+// which is at most the source area.
+func (r *Renderer) WarpBarrelBounds(sourceWidth, sourceHeight int, ox, oy float32) image.Rectangle {
+	return maskRectBounds(sourceWidth, sourceHeight, ox, oy, 0, 0, 0, 0)
+}
+
+// WarpArcBounds returns the area that [Renderer.WarpArc]() would touch.
+func (r *Renderer) WarpArcBounds(sourceWidth, sourceHeight int, cx, cy, outRadius float32, rads float64) image.Rectangle {
+	if outRadius < 0 {
+		return image.Rectangle{}
+	}
+	minX, minY, maxX, maxY, _, _ := warpArcRect(float32(sourceWidth), float32(sourceHeight), cx, cy, outRadius, rads)
+	return rectBounds(minX, minY, maxX, maxY)
+}
+
+// maskRectBounds returns the bounds of a mask of the given size drawn
+// at (ox, oy), extended by the given margins on each side.
+func maskRectBounds(maskWidth, maskHeight int, ox, oy, left, top, right, bottom float32) image.Rectangle {
+	return rectBounds(ox-left, oy-top, ox+float32(maskWidth)+right, oy+float32(maskHeight)+bottom)
+}
+
+// EffectMargins represents the padding, in pixels, that an effect can draw
+// beyond each side of its mask. This can be used to size the offscreens where
+// effects are drawn so they never get clipped:
+//
+//	margins := renderer.ShadowMargins(w, h, 4, 4, 6, shapes.ClampNone)
+//	offscreen := ebiten.NewImage(w+margins.Horz(), h+margins.Vert())
+//	ox, oy := float32(margins.Left), float32(margins.Top)
+//	renderer.ApplyShadow(offscreen, mask, ox, oy, 4, 4, 6, shapes.ClampNone)
+type EffectMargins struct {
+	Left, Top, Right, Bottom int
+}
+
+// Horz returns the sum of the left and right margins.
+func (m EffectMargins) Horz() int {
+	return m.Left + m.Right
+}
+
+// Vert returns the sum of the top and bottom margins.
+func (m EffectMargins) Vert() int {
+	return m.Top + m.Bottom
+}
+
+// Expand returns the given rect extended by the margins.
+func (m EffectMargins) Expand(rect image.Rectangle) image.Rectangle {
+	return image.Rect(rect.Min.X-m.Left, rect.Min.Y-m.Top, rect.Max.X+m.Right, rect.Max.Y+m.Bottom)
+}
+
+// Max returns the per-side maximum of both margins. This is useful when
+// multiple effects are drawn from the same mask.
+func (m EffectMargins) Max(other EffectMargins) EffectMargins {
+	return EffectMargins{
+		Left:   max(m.Left, other.Left),
+		Top:    max(m.Top, other.Top),
+		Right:  max(m.Right, other.Right),
+		Bottom: max(m.Bottom, other.Bottom),
+	}
+}
+
+// Add returns the per-side sum of both margins. This is useful when
+// effects are chained, using the result of one as the mask of the next.
+func (m EffectMargins) Add(other EffectMargins) EffectMargins {
+	return EffectMargins{
+		Left:   m.Left + other.Left,
+		Top:    m.Top + other.Top,
+		Right:  m.Right + other.Right,
+		Bottom: m.Bottom + other.Bottom,
+	}
+}
+
+// marginsFromBounds converts the bounds of an effect applied at (0, 0)
+// to the margins around a mask of the given size. Negative margins, for
+// sides where the effect stays within the mask, are clamped to zero.
+func marginsFromBounds(bounds image.Rectangle, maskWidth, maskHeight int) EffectMargins {
+	return EffectMargins{
+		Left:   max(-bounds.Min.X, 0),
+		Top:    max(-bounds.Min.Y, 0),
+		Right:  max(bounds.Max.X-maskWidth, 0),
+		Bottom: max(bounds.Max.Y-maskHeight, 0),
+	}
+}
+
+// ExpansionMargins returns the margins needed by [Renderer.ApplyExpansion]().
+func (r *Renderer) ExpansionMargins(maskWidth, maskHeight int, thickness float32) EffectMargins {
+	return marginsFromBounds(r.ExpansionBounds(maskWidth, maskHeight, 0, 0, thickness), maskWidth, maskHeight)
+}
+
+// ExpansionRectMargins returns the margins needed by [Renderer.ApplyExpansionRect]().
+func (r *Renderer) ExpansionRectMargins(maskWidth, maskHeight int, thickness float32) EffectMargins {
+	return marginsFromBounds(r.ExpansionRectBounds(maskWidth, maskHeight, 0, 0, thickness), maskWidth, maskHeight)
+}
+
+// OutlineMargins returns the margins needed by [Renderer.ApplyOutline]().
+func (r *Renderer) OutlineMargins(maskWidth, maskHeight int, thickness float32) EffectMargins {
+	return marginsFromBounds(r.OutlineBounds(maskWidth, maskHeight, 0, 0, thickness), maskWidth, maskHeight)
+}
+
+// BlurMargins returns the margins needed by [Renderer.ApplyBlur]().
+func (r *Renderer) BlurMargins(maskWidth, maskHeight int, radius float32) EffectMargins {
+	return marginsFromBounds(r.BlurBounds(maskWidth, maskHeight, 0, 0, radius), maskWidth, maskHeight)
+}
+
+// Blur2Margins returns the margins needed by [Renderer.ApplyBlur2]().
+func (r *Renderer) Blur2Margins(maskWidth, maskHeight int, radius float32) EffectMargins {
+	return marginsFromBounds(r.Blur2Bounds(maskWidth, maskHeight, 0, 0, radius), maskWidth, maskHeight)
+}
+
+// ShadowMargins returns the margins needed by [Renderer.ApplyShadow]().
+func (r *Renderer) ShadowMargins(maskWidth, maskHeight int, xOffset, yOffset, radius float32, clamping Clamping) EffectMargins {
+	return marginsFromBounds(r.ShadowBounds(maskWidth, maskHeight, 0, 0, xOffset, yOffset, radius, clamping), maskWidth, maskHeight)
+}
+
+// HardShadowMargins returns the margins needed by [Renderer.ApplyHardShadow]().
+func (r *Renderer) HardShadowMargins(maskWidth, maskHeight int, xOffset, yOffset float32) EffectMargins {
+	return marginsFromBounds(r.HardShadowBounds(maskWidth, maskHeight, 0, 0, xOffset, yOffset), maskWidth, maskHeight)
+}
+
+// ZoomShadowMargins returns the margins needed by [Renderer.ApplyZoomShadow]().
+func (r *Renderer) ZoomShadowMargins(maskWidth, maskHeight int, xOffset, yOffset, zoom float32, clamping Clamping) EffectMargins {
+	return marginsFromBounds(r.ZoomShadowBounds(maskWidth, maskHeight, 0, 0, xOffset, yOffset, zoom, clamping), maskWidth, maskHeight)
+}
+
+// GlowMargins returns the margins needed by [Renderer.ApplyGlow]() and [Renderer.ApplySimpleGlow]().
+func (r *Renderer) GlowMargins(maskWidth, maskHeight int, horzRadius, vertRadius float32) EffectMargins {
+	return marginsFromBounds(r.GlowBounds(maskWidth, maskHeight, 0, 0, horzRadius, vertRadius), maskWidth, maskHeight)
+}
+
+// HorzGlowMargins returns the margins needed by [Renderer.ApplyHorzGlow]() and
+// [Renderer.ApplyDarkHorzGlow]().
+func (r *Renderer) HorzGlowMargins(maskWidth, maskHeight int, horzRadius float32) EffectMargins {
+	return marginsFromBounds(r.HorzGlowBounds(maskWidth, maskHeight, 0, 0, horzRadius), maskWidth, maskHeight)
+}
+
+// BlurD4Margins returns the margins needed by [Renderer.ApplyBlurD4](), [Renderer.ApplyGlowD4]()
+// and [Renderer.ApplyColorGlowD4]().
+func (r *Renderer) BlurD4Margins(maskWidth, maskHeight int, horzKernel, vertKernel GaussKern) EffectMargins {
+	return marginsFromBounds(r.BlurD4Bounds(maskWidth, maskHeight, 0, 0, horzKernel, vertKernel), maskWidth, maskHeight)
+}
+
+// JFMExpandMargins returns the margins needed by [Renderer.JFMExpand](). Unlike
+// other effects, JFMExpand never draws outside the source bounds, so these margins
+// must be included as transparent padding within the source itself.
+func (r *Renderer) JFMExpandMargins(thickness float32) EffectMargins {
+	margin := int(math.Ceil(float64(thickness)))
+	return EffectMargins{Left: margin, Top: margin, Right: margin, Bottom: margin}
+}
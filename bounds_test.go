@@ -0,0 +1,265 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestBounds(t *testing.T) {
+	r := NewRenderer()
+	legacy := NewRenderer()
+	legacy.SetLegacyRounding(true)
+	pixelPerfect := NewRenderer()
+	pixelPerfect.SetPixelPerfect(true)
+	clamped := NewRenderer()
+	clamped.SetSourceClamping(ClampAll)
+	topLeft := NewRenderer()
+	topLeft.SetSourceClamping(ClampTopLeft)
+
+	tests := []struct {
+		name string
+		got  image.Rectangle
+		want image.Rectangle
+	}{
+		{"area", r.AreaBounds(10, 10, 20, 20, 0), image.Rect(10, 10, 30, 30)},
+		{"area/negative-size", r.AreaBounds(10, 10, -5, 5, 2), image.Rect(3, 8, 12, 17)},
+		{"area/inner-rounding", r.AreaBounds(10, 10, 20, 20, -4), image.Rect(10, 10, 30, 30)},
+		{"area/legacy", legacy.AreaBounds(10, 10, 20, 20, 4), image.Rect(10, 10, 30, 30)},
+		{"circle", r.CircleBounds(10.3, 10.3, 5), image.Rect(5, 5, 16, 16)},
+		{"circle/pixel-perfect", pixelPerfect.CircleBounds(10.3, 10.3, 3), image.Rect(7, 7, 14, 14)},
+		{"stroke-circle", r.StrokeCircleBounds(50, 50, 10, 3), image.Rect(38, 38, 62, 62)},
+		{"ring/empty", r.RingBounds(50, 50, 10, 10), image.Rectangle{}},
+		{"line", r.LineBounds(10, 10, 30, 10, 4), image.Rect(8, 8, 32, 12)},
+		{"pie", r.PieBounds(50, 50, 40, RadsRight, RadsBottom, 0), image.Rect(50, 50, 90, 90)},
+		{"pie/outer-rounding", r.PieBounds(50, 50, 40, RadsRight, RadsBottom, 4), image.Rect(46, 46, 94, 94)},
+		{"pie/full", r.PieBounds(50, 50, 40, 0, 2*math.Pi, 4), image.Rect(10, 10, 90, 90)},
+		{"pie-rate", r.PieRateBounds(50, 50, 40, RadsBottomRight, 0.25, 0), image.Rect(50, 50, 90, 90)},
+		{"stroke-pie", r.StrokePieBounds(50, 50, 40, 2, RadsRight, RadsBottom, 0), image.Rect(48, 48, 92, 92)},
+		{"ring-sector", r.RingSectorBounds(50, 50, 20, 40, RadsTopRight, RadsBottomRight, 0), image.Rect(64, 21, 90, 79)},
+		{"ring-sector/no-hole", r.RingSectorBounds(50, 50, 0, 40, RadsRight, RadsBottom, 0), image.Rect(50, 50, 90, 90)},
+		{"triangle", r.TriangleBounds(10, 60, 50, 10, 90, 60, 8), image.Rect(2, 2, 98, 68)},
+		{"triangle/degenerate", r.TriangleBounds(10, 10, 20, 20, 30, 30, 0), image.Rectangle{}},
+		{"hexagon", r.HexagonBounds(50, 50, 30, -4), image.Rect(20, 20, 80, 80)},
+		{"tapered-line", r.TaperedLineBounds(10, 10, 30, 10, 4, 8), image.Rect(8, 6, 34, 14)},
+		{"tapered-line/contained", r.TaperedLineBounds(10, 10, 12, 10, 20, 2), image.Rect(0, 0, 20, 20)},
+		{"sdf", r.SDFBounds(SDFCircle(20, 20, 10)), image.Rect(9, 9, 31, 31)},
+		{"sdf/empty", r.SDFBounds(SDFIntersection(SDFCircle(0, 0, 5), SDFCircle(20, 0, 5))), image.Rectangle{}},
+		{"mask-circle", r.MaskCircleBounds(20, 10, 50, 50, 2, 0, 8, 2), image.Rect(42, 45, 62, 55)},
+		{"mask-circle/empty", r.MaskCircleBounds(20, 10, 50, 50, 0, 0, -1, 0), image.Rectangle{}},
+		{"map-quad4", r.MapQuad4Bounds([4]PointF32{{X: 10, Y: 12}, {X: 30.5, Y: 10}, {X: 28, Y: 30}, {X: 12, Y: 28}}), image.Rect(10, 10, 31, 30)},
+		{"expansion", r.ExpansionBounds(20, 10, 0, 0, 4), image.Rect(-3, -3, 23, 13)},
+		{"expansion-rect", r.ExpansionRectBounds(20, 10, 0, 0, 2.5), image.Rect(-3, -3, 23, 13)},
+		{"shadow", r.ShadowBounds(20, 10, 5, 5, 3, -2, 4, ClampNone), image.Rect(3, 1, 30, 17)},
+		{"shadow/clamped", r.ShadowBounds(20, 10, 5, 5, 3, -2, 4, ClampTopLeft), image.Rect(5, 3, 30, 17)},
+		{"hard-shadow", r.HardShadowBounds(20, 10, 5, 5, -3, 2), image.Rect(2, 5, 25, 17)},
+		{"zoom-shadow", r.ZoomShadowBounds(20, 10, 0, 0, 0, 0, 2, ClampBottom), image.Rect(-10, -5, 30, 10)},
+		{"horz-glow", r.HorzGlowBounds(20, 10, 0, 0, 4), image.Rect(-3, 0, 23, 10)},
+		{"blur2", r.Blur2Bounds(20, 10, 0, 0, 5), image.Rect(-3, -3, 23, 13)},
+		{"glow", r.GlowBounds(20, 10, 0, 0, 4, 4), image.Rect(-2, -3, 22, 13)},
+		{"expansion/source-clamped", clamped.ExpansionBounds(20, 10, 0, 0, 4), image.Rect(0, 0, 20, 10)},
+		{"blur/source-clamped", topLeft.BlurBounds(20, 10, 0, 0, 4), image.Rect(0, 0, 23, 13)},
+		{"glow/source-clamped", clamped.GlowBounds(20, 10, 0, 0, 4, 4), image.Rect(0, 0, 20, 10)},
+		{"shadow/source-clamped", clamped.ShadowBounds(20, 10, 5, 5, 3, -2, 4, ClampNone), image.Rect(5, 3, 28, 15)},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestEffectMargins(t *testing.T) {
+	r := NewRenderer()
+	clamped := NewRenderer()
+	clamped.SetSourceClamping(ClampAll)
+	topLeft := NewRenderer()
+	topLeft.SetSourceClamping(ClampTopLeft)
+	tests := []struct {
+		name string
+		got  EffectMargins
+		want EffectMargins
+	}{
+		{"expansion", r.ExpansionMargins(20, 10, 4), EffectMargins{3, 3, 3, 3}},
+		{"shadow", r.ShadowMargins(20, 10, 3, -2, 4, ClampNone), EffectMargins{2, 4, 5, 2}},
+		{"shadow/clamped", r.ShadowMargins(20, 10, 3, -2, 4, ClampTopLeft), EffectMargins{0, 2, 5, 2}},
+		{"hard-shadow", r.HardShadowMargins(20, 10, 3, 2), EffectMargins{0, 0, 3, 2}},
+		{"zoom-shadow", r.ZoomShadowMargins(20, 10, 0, 0, 2, ClampBottom), EffectMargins{10, 5, 10, 0}},
+		{"horz-glow", r.HorzGlowMargins(20, 10, 4), EffectMargins{3, 0, 3, 0}},
+		{"blur/source-clamped", clamped.BlurMargins(20, 10, 4), EffectMargins{}},
+		{"blur2/source-clamped", topLeft.Blur2Margins(20, 10, 5), EffectMargins{0, 0, 3, 3}},
+		{"jfm-expand", r.JFMExpandMargins(2.5), EffectMargins{3, 3, 3, 3}},
+		{"max", EffectMargins{1, 5, 2, 0}.Max(EffectMargins{3, 1, 2, 4}), EffectMargins{3, 5, 2, 4}},
+		{"add", EffectMargins{1, 5, 2, 0}.Add(EffectMargins{3, 1, 2, 4}), EffectMargins{4, 6, 4, 4}},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, test.got, test.want)
+		}
+	}
+
+	margins := EffectMargins{Left: 1, Top: 2, Right: 3, Bottom: 4}
+	if got, want := margins.Expand(image.Rect(10, 10, 20, 20)), image.Rect(9, 8, 23, 24); got != want {
+		t.Errorf("Expand: got %v, want %v", got, want)
+	}
+	if margins.Horz() != 4 || margins.Vert() != 6 {
+		t.Errorf("Horz/Vert: got %d/%d, want 4/6", margins.Horz(), margins.Vert())
+	}
+}
+
+// go test -run ^TestBoundsContainDraws$ . -count 1
+func TestBoundsContainDraws(t *testing.T) {
+	const size = 160
+	quad := [4]PointF32{{X: 34, Y: 32}, {X: 120, Y: 44}, {X: 110, Y: 126}, {X: 40, Y: 106}}
+
+	var mask, jfmap *ebiten.Image
+	pts := []PointF32{{X: 30, Y: 40}, {X: 80, Y: 30}, {X: 90, Y: 90}, {X: 130, Y: 120}}
+	widths := []float32{4, 12, 16, 0}
+	parametric := func(t float64) PointF32 {
+		s, c := math.Sincos(t)
+		return PointF32{X: 80 + float32(50*c), Y: 80 + float32(30*s)}
+	}
+	balls := []PointF32{{X: 60, Y: 70}, {X: 96, Y: 84}}
+	sdf := SDFSmoothUnion(SDFCircle(60, 70, 24), SDFBox(80, 60, 50, 40), 10)
+	cases := []struct {
+		name   string
+		bounds func(r *Renderer) image.Rectangle
+		draw   func(r *Renderer, target *ebiten.Image)
+	}{
+		{"area", func(r *Renderer) image.Rectangle { return r.AreaBounds(30.5, 40.2, 60, 50, 6) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, 30.5, 40.2, 60, 50, 6) }},
+		{"stroke area", func(r *Renderer) image.Rectangle { return r.StrokeAreaBounds(30.5, 40.2, 60, 50, 3, 2, 4) },
+			func(r *Renderer, target *ebiten.Image) { r.StrokeArea(target, 30.5, 40.2, 60, 50, 3, 2, 4) }},
+		{"line", func(r *Renderer) image.Rectangle { return r.LineBounds(30, 120, 130, 44, 7) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawLine(target, 30, 120, 130, 44, 7) }},
+		{"circle", func(r *Renderer) image.Rectangle { return r.CircleBounds(80.4, 80.7, 30.2) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, 80.4, 80.7, 30.2) }},
+		{"stroke circle", func(r *Renderer) image.Rectangle { return r.StrokeCircleBounds(80.4, 80.7, 30.2, 5) },
+			func(r *Renderer, target *ebiten.Image) { r.StrokeCircle(target, 80.4, 80.7, 30.2, 5) }},
+		{"ring", func(r *Renderer) image.Rectangle { return r.RingBounds(80, 80, 20, 44.5) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawRing(target, 80, 80, 20, 44.5) }},
+		{"ring sector", func(r *Renderer) image.Rectangle { return r.RingSectorBounds(80, 80, 24, 56, 0.3, 2.5, 4) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawRingSector(target, 80, 80, 24, 56, 0.3, 2.5, 4) }},
+		{"stroke ring sector", func(r *Renderer) image.Rectangle { return r.StrokeRingSectorBounds(80, 80, 24, 56, 4, 0.3, 2.5, 0) },
+			func(r *Renderer, target *ebiten.Image) { r.StrokeRingSector(target, 80, 80, 24, 56, 4, 0.3, 2.5, 0) }},
+		{"ring sector without hole", func(r *Renderer) image.Rectangle { return r.RingSectorBounds(80, 80, 0, 56, 0.3, 2.5, 4) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawRingSector(target, 80, 80, 0, 56, 0.3, 2.5, 4) }},
+		{"stroke ring sector without hole", func(r *Renderer) image.Rectangle { return r.StrokeRingSectorBounds(80, 80, 0, 56, 4, 0.3, 2.5, 0) },
+			func(r *Renderer, target *ebiten.Image) { r.StrokeRingSector(target, 80, 80, 0, 56, 4, 0.3, 2.5, 0) }},
+		{"pie", func(r *Renderer) image.Rectangle { return r.PieBounds(80, 80, 56, RadsBottomLeft, RadsTopRight, 5) },
+			func(r *Renderer, target *ebiten.Image) {
+				r.DrawPie(target, 80, 80, 56, RadsBottomLeft, RadsTopRight, 5)
+			}},
+		{"pie rate", func(r *Renderer) image.Rectangle { return r.PieRateBounds(80, 80, 56, RadsTop, 0.3, -6) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawPieRate(target, 80, 80, 56, RadsTop, 0.3, -6) }},
+		{"stroke pie", func(r *Renderer) image.Rectangle {
+			return r.StrokePieBounds(80, 80, 56, 4, RadsRight, RadsBottomLeft, 3)
+		},
+			func(r *Renderer, target *ebiten.Image) {
+				r.StrokePie(target, 80, 80, 56, 4, RadsRight, RadsBottomLeft, 3)
+			}},
+		{"ellipse", func(r *Renderer) image.Rectangle { return r.EllipseBounds(80, 80, 60, 24, 0.6) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawEllipse(target, 80, 80, 60, 24, 0.6) }},
+		{"triangle", func(r *Renderer) image.Rectangle { return r.TriangleBounds(30, 130, 80, 30, 136, 110, 6) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawTriangle(target, 30, 130, 80, 30, 136, 110, 6) }},
+		{"stroke triangle", func(r *Renderer) image.Rectangle { return r.StrokeTriangleBounds(30, 130, 80, 30, 136, 110, 5, 0) },
+			func(r *Renderer, target *ebiten.Image) { r.StrokeTriangle(target, 30, 130, 80, 30, 136, 110, 5, 0) }},
+		{"hexagon", func(r *Renderer) image.Rectangle { return r.HexagonBounds(80, 80, 50, 4) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawHexagon(target, 80, 80, 50, 4, 0.3) }},
+		{"quad", func(r *Renderer) image.Rectangle { return r.QuadBounds(quad, 6) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawQuad(target, quad, 6) }},
+		{"quad soft", func(r *Renderer) image.Rectangle { return r.QuadSoftBounds(quad, -6, 4) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawQuadSoft(target, quad, -6, 4) }},
+		{"triangle colors", func(r *Renderer) image.Rectangle { return r.TriangleColorsBounds(30, 130, 80, 30, 136, 110, 6) },
+			func(r *Renderer, target *ebiten.Image) {
+				r.DrawTriangleColors(target, 30, 130, 80, 30, 136, 110, 6, color.White, color.White, color.White)
+			}},
+		{"tapered line", func(r *Renderer) image.Rectangle { return r.TaperedLineBounds(30, 120, 130, 44, 4, 18) },
+			func(r *Renderer, target *ebiten.Image) {
+				r.DrawTaperedLine(target, 30, 120, 130, 44, 4, 18, color.White, color.White)
+			}},
+		{"ribbon", func(r *Renderer) image.Rectangle { return r.RibbonBounds(pts, widths) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawRibbon(target, pts, widths, nil) }},
+		{"parametric", func(r *Renderer) image.Rectangle { return r.ParametricBounds(parametric, 0, 5, 6) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawParametric(target, parametric, 0, 5, 6) }},
+		{"metaballs", func(r *Renderer) image.Rectangle { return r.MetaballsBounds(balls, []float32{20, 14}, 0.8) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawMetaballs(target, balls, []float32{20, 14}, 0.8, 4) }},
+		{"sdf", func(r *Renderer) image.Rectangle { return r.SDFBounds(sdf) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawSDF(target, sdf) }},
+		{"expansion", func(r *Renderer) image.Rectangle { return r.ExpansionBounds(40, 40, 50, 50, 6) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyExpansion(target, mask, 50, 50, 6) }},
+		{"expansion rect", func(r *Renderer) image.Rectangle { return r.ExpansionRectBounds(40, 40, 50, 50, 6) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyExpansionRect(target, mask, 50, 50, 6) }},
+		{"outline", func(r *Renderer) image.Rectangle { return r.OutlineBounds(40, 40, 50, 50, 6) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyOutline(target, mask, 50, 50, 6) }},
+		{"blur", func(r *Renderer) image.Rectangle { return r.BlurBounds(40, 40, 50, 50, 8) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyBlur(target, mask, 50, 50, 8, 1) }},
+		{"blur2", func(r *Renderer) image.Rectangle { return r.Blur2Bounds(40, 40, 50, 50, 12) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyBlur2(target, mask, 50, 50, 12, 1) }},
+		{"shadow", func(r *Renderer) image.Rectangle { return r.ShadowBounds(40, 40, 50, 50, 8, -6, 6, ClampNone) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyShadow(target, mask, 50, 50, 8, -6, 6, ClampNone) }},
+		{"zoom shadow", func(r *Renderer) image.Rectangle { return r.ZoomShadowBounds(40, 40, 50, 50, 4, 4, 1.5, ClampNone) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyZoomShadow(target, mask, 50, 50, 4, 4, 1.5, ClampNone) }},
+		{"glow", func(r *Renderer) image.Rectangle { return r.GlowBounds(40, 40, 50, 50, 10, 10) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyGlow(target, mask, 50, 50, 10, 10, 0, 0.5, 1) }},
+		{"horz glow", func(r *Renderer) image.Rectangle { return r.HorzGlowBounds(40, 40, 50, 50, 10) },
+			func(r *Renderer, target *ebiten.Image) { r.ApplyHorzGlow(target, mask, 50, 50, 10, 0, 0.5, 1) }},
+		{"blur d4", func(r *Renderer) image.Rectangle { return r.BlurD4Bounds(40, 40, 50, 50, GaussKern7, GaussKern5) },
+			func(r *Renderer, target *ebiten.Image) {
+				r.ApplyBlurD4(target, mask, 50, 50, GaussKern7, GaussKern5, 1)
+			}},
+		{"mask", func(r *Renderer) image.Rectangle { return r.MaskBounds(40, 40, 50.5, 50.5) },
+			func(r *Renderer, target *ebiten.Image) { r.Mask(target, mask, mask, 50.5, 50.5) }},
+		{"mask at", func(r *Renderer) image.Rectangle { return r.MaskAtBounds(40, 40, 50, 50) },
+			func(r *Renderer, target *ebiten.Image) { r.MaskAt(target, mask, mask, 50, 50, 40, 40) }},
+		{"mask horz", func(r *Renderer) image.Rectangle { return r.MaskHorzBounds(40, 40, 50, 50) },
+			func(r *Renderer, target *ebiten.Image) { r.MaskHorz(target, mask, 50, 50, 60, 80) }},
+		{"mask circle", func(r *Renderer) image.Rectangle { return r.MaskCircleBounds(40, 40, 80, 80, 6, -4, 14, 4) },
+			func(r *Renderer, target *ebiten.Image) { r.MaskCircle(target, mask, 80, 80, 6, -4, 14, 4) }},
+		{"jfm heat", func(r *Renderer) image.Rectangle { return r.JFMHeatBounds(40, 40, 50, 50) },
+			func(r *Renderer, target *ebiten.Image) { r.JFMHeat(target, jfmap, 50, 50, 16) }},
+		{"jfm expand", func(r *Renderer) image.Rectangle { return r.JFMExpandBounds(40, 40, 50, 50) },
+			func(r *Renderer, target *ebiten.Image) { r.JFMExpand(target, mask, nil, 50, 50, 6, AAMargin) }},
+		{"map quad4", func(r *Renderer) image.Rectangle { return r.MapQuad4Bounds(quad) },
+			func(r *Renderer, target *ebiten.Image) { r.MapQuad4(target, mask, quad) }},
+		{"map projective", func(r *Renderer) image.Rectangle { return r.MapProjectiveBounds(quad) },
+			func(r *Renderer, target *ebiten.Image) { r.MapProjective(target, mask, quad) }},
+		{"warp barrel", func(r *Renderer) image.Rectangle { return r.WarpBarrelBounds(40, 40, 50, 50) },
+			func(r *Renderer, target *ebiten.Image) { r.WarpBarrel(target, mask, 50, 50, 0.5, 0.5) }},
+		{"warp pincushion", func(r *Renderer) image.Rectangle { return r.WarpBarrelBounds(40, 40, 50, 50) },
+			func(r *Renderer, target *ebiten.Image) { r.WarpBarrel(target, mask, 50, 50, -0.5, -0.5) }},
+		{"warp arc", func(r *Renderer) image.Rectangle { return r.WarpArcBounds(40, 40, 80, 80, 56, RadsTop) },
+			func(r *Renderer, target *ebiten.Image) { r.WarpArc(target, mask, 80, 80, 56, RadsTop) }},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		mask = r.NewCircle(20)
+		jfmap = ebiten.NewImage(40, 40)
+		r.JFMCompute(jfmap, mask, JFMBoundary, 16, 0.001, 1.0)
+		img := ebiten.NewImage(size, size)
+		pix := make([]byte, size*size*4)
+		for _, c := range cases {
+			img.Clear()
+			c.draw(r, img)
+			img.ReadPixels(pix)
+			bounds := c.bounds(r)
+			if isTransparent(pix) {
+				fail("%s: nothing drawn", c.name)
+				continue
+			}
+		pixels:
+			for y := range size {
+				for x := range size {
+					if pix[(y*size+x)*4+3] > 0 && !image.Pt(x, y).In(bounds) {
+						fail("%s: pixel drawn outside of %v", c.name, bounds)
+						break pixels
+					}
+				}
+			}
+		}
+	})
+}
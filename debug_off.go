@@ -0,0 +1,6 @@
+//go:build !shapesdebug
+
+package shapes
+
+// debugChecks is disabled by default. See debug_on.go.
+const debugChecks = false
@@ -0,0 +1,8 @@
+//go:build shapesdebug
+
+package shapes
+
+// debugChecks enables additional safety checks that panic on misuse, like
+// passing an internal offscreen to a function that will overwrite it. Enable
+// them with the shapesdebug build tag: go build -tags shapesdebug
+const debugChecks = true
@@ -4,4 +4,74 @@
 // Kage shaders instead of raw triangles rasterization. This means rendering tends to be
 // smoother, but extra care has to be taken as changing shaders or some of its parameters will
 // break batching.
+//
+// # Rounding conventions
+//
+// Shapes that accept a rounding parameter follow the same convention: positive values
+// expand the shape's SDF, rounding the corners while growing the shape by the given
+// amount in all directions, and negative values do inner rounding, cutting the corners
+// while preserving the original shape bounds. Older versions of the package used positive
+// values for inner rounding on some shapes; see [Renderer.SetLegacyRounding]() if you
+// need to migrate code that relied on that.
+//
+// # Image origins
+//
+// All coordinates passed to draw and apply functions are relative to the target's
+// origin. In other words, drawing at (0, 0) on a subimage with bounds starting at
+// (32, 16) will draw at (32, 16) on the underlying image. Sources and masks are
+// always read from their own bounds, no matter where those are located.
+//
+// Effects never sample pixels outside their source bounds: those are treated as
+// transparent, so subimages from sprite sheets and atlases can be passed directly as
+// masks without neighboring sprites bleeding into blurs, glows or outlines. Use
+// [Renderer.SetSourceClamping]() if you want the edge pixels to be extended instead,
+// like when blurring a background that covers the whole screen.
+//
+// # Bounds
+//
+// Most draw and apply functions have a bounds counterpart, like [Renderer.PieBounds]()
+// or [Renderer.ShadowBounds](), returning the image.Rectangle that the function would
+// touch for the given parameters. Functions that draw images, like [Renderer.MaskBounds]()
+// or [Renderer.WarpArcBounds](), take the image sizes instead of the images themselves.
+// These can be used to size offscreens, redraw dirty rects or cull shapes that would be
+// drawn outside the screen. Effects also have margin functions, like
+// [Renderer.ShadowMargins](), that return the [EffectMargins] needed around a mask so
+// the effect doesn't get clipped.
+//
+// # Transforms
+//
+// [Renderer.SetGeoM]() sets a transform applied to all draws, so shapes and effects can
+// be drawn in world coordinates for cameras, minimaps and zoomable views. Shapes keep
+// their exact SDFs under rotation and scaling, and the soft edge stays in target pixels.
+// The transform is saved by [Renderer.Push](), which can be used as a transform stack.
+// Bounds and hit test functions are not affected, and keep working in local coordinates.
+//
+// Similarly, [Renderer.SetClipRect]() restricts all draws to a rect of the target, which
+// is useful for scrolling lists and other UI panels. Triangles are clipped on the CPU, so
+// batching is preserved and draws fully outside the rect are skipped. For other shapes,
+// like rounded cards, [Renderer.BeginClip]() and [Renderer.EndClip]() draw a whole subtree
+// into an offscreen layer and composite it through an antialiased shape or alpha mask.
+//
+// # Offscreens
+//
+// Some effects need internal offscreens, which are kept in an [OffscreenPool] and
+// reused across calls. Pools can be shared between renderers with [Renderer.SetOffscreenPool](),
+// and can also lease offscreens for your own temporary drawing with [OffscreenPool.Lease]().
+// Offscreens only grow by default, but they can be freed with [Renderer.ReleaseTemps](),
+// or automatically with [OffscreenPool.SetShrinkPolicy](). [OffscreenPool.SetMemoryBudget]()
+// can be used to detect scenes that use too much offscreen memory.
+// Building with the shapesdebug tag enables additional checks, like detecting internal
+// offscreens from [Renderer.UnsafeTemp]() being passed back to the functions that use them.
+//
+// # Batching
+//
+// Ebitengine can only merge consecutive draws into a single draw call when they use
+// the same shader, uniforms and images. Areas, lines, triangles, hexagons and quads
+// pass their parameters as vertex attributes, so consecutive draws of the same kind
+// can be batched even if their sizes or roundings change, as long as the soft edge
+// and blend don't change. When drawing many shapes of the same kind,
+// like particles, a [ShapeBatch] can be used to pass all the shape parameters as vertex
+// attributes and draw them in a single call. With [ShapeBatch.SetUberShader](), mixed
+// circles, rects, rings and lines can also be drawn in a single call. To find out
+// which draws are breaking batching, see [Renderer.Stats]().
 package shapes
@@ -34,6 +34,15 @@ func ceilF32(x float32) float32 {
 	return float32(math.Ceil(float64(x)))
 }
 
+func roundF32(x float32) float32 {
+	return float32(math.Round(float64(x)))
+}
+
+// snapToPixelCenter returns the center of the pixel containing the given coordinate.
+func snapToPixelCenter[Float float32 | float64](x Float) Float {
+	return Float(math.Floor(float64(x))) + 0.5
+}
+
 func abs[Float float32 | float64](a Float) Float {
 	if a < 0 {
 		return -a
@@ -105,6 +114,26 @@ func parallelsAtDist(a, b, c float64, dist float64) (float64, float64) {
 	return c - shift, c + shift
 }
 
+// insetTriangle moves the edges of the given triangle inwards by the given
+// distance, or outwards if the distance is negative, and returns the new vertices.
+func insetTriangle(ox1, oy1, ox2, oy2, ox3, oy3, dist float64) (float64, float64, float64, float64, float64, float64) {
+	a12, b12, c12 := toLinearFormABC(ox1, oy1, ox2, oy2)
+	a23, b23, c23 := toLinearFormABC(ox2, oy2, ox3, oy3)
+	a31, b31, c31 := toLinearFormABC(ox3, oy3, ox1, oy1)
+	c1_12, c2_12 := parallelsAtDist(a12, b12, c12, dist)
+	c1_23, c2_23 := parallelsAtDist(a23, b23, c23, dist)
+	c1_31, c2_31 := parallelsAtDist(a31, b31, c31, dist)
+	if a12*ox3+b12*oy3+c12 > 0 { // fancy winding order test
+		c12, c23, c31 = c1_12, c1_23, c1_31
+	} else {
+		c12, c23, c31 = c2_12, c2_23, c2_31
+	}
+	iox1, ioy1 := shortCramer(a31, b31, c31, a12, b12, c12)
+	iox2, ioy2 := shortCramer(a12, b12, c12, a23, b23, c23)
+	iox3, ioy3 := shortCramer(a23, b23, c23, a31, b31, c31)
+	return iox1, ioy1, iox2, ioy2, iox3, ioy3
+}
+
 func snapEdges[Float ~float32 | ~float64](value, min, max, tolerance Float) Float {
 	switch {
 	case value+tolerance > max:
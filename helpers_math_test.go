@@ -1,6 +1,9 @@
 package shapes
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestGaussSolver8x8(t *testing.T) {
 	const tolerance float32 = 1e-6
@@ -135,3 +138,29 @@ func TestComputeHomography(t *testing.T) {
 		}
 	}
 }
+
+func TestInsetTriangle(t *testing.T) {
+	const tolerance = 1e-9
+	sqrt2 := math.Sqrt2
+
+	var tests = []struct {
+		in   [6]float64
+		dist float64
+		out  [6]float64
+	}{
+		{[6]float64{0, 0, 10, 0, 0, 10}, 1, [6]float64{1, 1, 9 - sqrt2, 1, 1, 9 - sqrt2}},
+		{[6]float64{0, 0, 0, 10, 10, 0}, 1, [6]float64{1, 1, 1, 9 - sqrt2, 9 - sqrt2, 1}},
+		{[6]float64{0, 0, 10, 0, 0, 10}, -1, [6]float64{-1, -1, 11 + sqrt2, -1, -1, 11 + sqrt2}},
+		{[6]float64{0, 0, 10, 0, 0, 10}, 0, [6]float64{0, 0, 10, 0, 0, 10}},
+	}
+
+	for i, test := range tests {
+		x1, y1, x2, y2, x3, y3 := insetTriangle(test.in[0], test.in[1], test.in[2], test.in[3], test.in[4], test.in[5], test.dist)
+		got := [6]float64{x1, y1, x2, y2, x3, y3}
+		for j := range got {
+			if math.Abs(got[j]-test.out[j]) > tolerance {
+				t.Fatalf("test#%d: expected %v, got %v", i, test.out, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package shapes
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// polygonMesh builds the geometry used by the triangle and quad shaders to draw
+// rounded convex polygons without any uniforms, so consecutive draws can be batched
+// even if their parameters change. The geometry is made of the polygon itself, plus
+// bands along its edges and wedges at its corners extending 'extent' pixels outwards.
+//
+// Each vertex gets the signed distances to the polygon edge lines, minus the rounding,
+// as custom VAs (one per edge, up to 4), and the vertices of the corner wedges get
+// their position relative to the corner as source coordinates. Distances are affine,
+// so after interpolation the shaders can compute the exact SDF of the rounded polygon
+// as length(sourceCoords) + max(customVAs).
+//
+// The polygon must be convex, with 3 or 4 vertices in any winding order. Vertex colors
+// are set through colorAt, and the target's origin must be given as (dstOX, dstOY).
+func (r *Renderer) polygonMesh(dstOX, dstOY float32, poly []PointF32, rounding, extent float32, colorAt func(PointF32) [4]float32) ([]ebiten.Vertex, []uint16) {
+	const minEdgeLen = 1e-4
+
+	n := len(poly)
+	if n < 3 || n > 4 {
+		panic("polygonMesh only supports triangles and quads")
+	}
+
+	// outward normals for each edge. degenerate edges are skipped
+	var area float32
+	for i := range n {
+		area += cross2F32(poly[i], poly[(i+1)%n])
+	}
+	var normals, dirs [4]PointF32
+	var valid [4]bool
+	firstValid := -1
+	for i := range n {
+		edge := poly[(i+1)%n].Sub(poly[i])
+		if edge.Length() < minEdgeLen {
+			continue
+		}
+		dirs[i] = edge.Normalize()
+		normals[i] = PointF32{X: dirs[i].Y, Y: -dirs[i].X}
+		if area < 0 {
+			normals[i] = normals[i].Scale(-1)
+		}
+		valid[i] = true
+		if firstValid == -1 {
+			firstValid = i
+		}
+	}
+
+	// each valid edge gets a band and a corner split in two wedges
+	numQuads := 1
+	for i := range n {
+		if valid[i] {
+			numQuads += 3
+		}
+	}
+	vertices := r.getAuxVertices(n + numQuads*4)[:0]
+	indices := r.getAuxIndices((n-2)*3 + numQuads*6)[:0]
+
+	appendVertex := func(pt, src PointF32, customs [4]float32) {
+		clr := colorAt(pt)
+		vertices = append(vertices, ebiten.Vertex{
+			DstX: dstOX + pt.X, DstY: dstOY + pt.Y,
+			SrcX: src.X, SrcY: src.Y,
+			ColorR: clr[0], ColorG: clr[1], ColorB: clr[2], ColorA: clr[3],
+			Custom0: customs[0], Custom1: customs[1], Custom2: customs[2], Custom3: customs[3],
+		})
+	}
+	replicate := func(value float32) [4]float32 {
+		var customs [4]float32
+		for i := range n {
+			customs[i] = value
+		}
+		return customs
+	}
+	appendQuad := func(pts, srcs [4]PointF32, customs func(PointF32) [4]float32) {
+		base := uint16(len(vertices))
+		for i, pt := range pts {
+			appendVertex(pt, srcs[i], customs(pt))
+		}
+		indices = append(indices, base, base+1, base+2, base, base+2, base+3)
+	}
+	lineDist := func(edge int, pt PointF32) float32 {
+		return pt.Sub(poly[edge]).Dot(normals[edge])
+	}
+
+	// polygon collapsed to a point, draw a circle
+	if firstValid == -1 {
+		if extent > 0 {
+			center, ext := poly[0], PointF32{X: extent, Y: extent}
+			minPt, maxPt := center.Sub(ext), center.Add(ext)
+			pts := rectCorners(minPt.X, minPt.Y, maxPt.X, maxPt.Y)
+			srcs := rectCorners(-extent, -extent, extent, extent)
+			appendQuad(pts, srcs, func(PointF32) [4]float32 { return replicate(-rounding) })
+		}
+		return vertices, indices
+	}
+
+	// polygon, with degenerate edges using the distance
+	// to another edge so they never affect the max
+	for _, pt := range poly {
+		var customs [4]float32
+		for i := range n {
+			if valid[i] {
+				customs[i] = lineDist(i, pt) - rounding
+			} else {
+				customs[i] = lineDist(firstValid, pt) - rounding
+			}
+		}
+		appendVertex(pt, PointF32{}, customs)
+	}
+	for i := 1; i < n-1; i++ {
+		indices = append(indices, 0, uint16(i), uint16(i+1))
+	}
+	if extent <= 0 {
+		return vertices, indices
+	}
+
+	// edge bands
+	for i := range n {
+		if !valid[i] {
+			continue
+		}
+		start, end := poly[i], poly[(i+1)%n]
+		offset := normals[i].Scale(extent)
+		appendQuad([4]PointF32{start, end, end.Add(offset), start.Add(offset)}, [4]PointF32{}, func(pt PointF32) [4]float32 {
+			return replicate(lineDist(i, pt) - rounding)
+		})
+	}
+
+	// corner wedges between consecutive valid edges, split in two
+	// halves so each half spans at most 90 degrees and its bounding
+	// kite stays small even for very sharp corners
+	wedgeCustoms := func(PointF32) [4]float32 { return replicate(-rounding) }
+	appendWedge := func(corner, na, nb PointF32) {
+		tip := na.Add(nb).Scale(extent / (1 + na.Dot(nb)))
+		srcs := [4]PointF32{{}, na.Scale(extent), tip, nb.Scale(extent)}
+		var pts [4]PointF32
+		for i, src := range srcs {
+			pts[i] = corner.Add(src)
+		}
+		appendQuad(pts, srcs, wedgeCustoms)
+	}
+	for i := range n {
+		if !valid[i] {
+			continue
+		}
+		next := (i + 1) % n
+		for !valid[next] {
+			next = (next + 1) % n
+		}
+		corner, na, nb := poly[next], normals[i], normals[next]
+		mid := na.Add(nb)
+		if mid.Length() < minEdgeLen {
+			mid = dirs[i] // edges going back and forth
+		}
+		mid = mid.Normalize()
+		appendWedge(corner, na, mid)
+		appendWedge(corner, mid, nb)
+	}
+	return vertices, indices
+}
+
+// quadColorAt returns a function that computes the color that the renderer's
+// vertex colors would have at any point when drawn over the given quad, as
+// interpolated by the GPU with the renderer's default indices.
+func (r *Renderer) quadColorAt(quad [4]PointF32) func(PointF32) [4]float32 {
+	c0, c1, c2, c3 := r.vertexColor(0), r.vertexColor(1), r.vertexColor(2), r.vertexColor(3)
+	if r.singleClr {
+		return func(PointF32) [4]float32 { return c0 }
+	}
+	first := triangleColorAt(quad[0], quad[1], quad[2], c0, c1, c2)
+	second := triangleColorAt(quad[0], quad[2], quad[3], c0, c2, c3)
+	diagonal := quad[2].Sub(quad[0])
+	firstSide := cross2F32(diagonal, quad[1].Sub(quad[0]))
+	return func(pt PointF32) [4]float32 {
+		if cross2F32(diagonal, pt.Sub(quad[0]))*firstSide >= 0 {
+			return first(pt)
+		}
+		return second(pt)
+	}
+}
+
+func (r *Renderer) vertexColor(index int) [4]float32 {
+	v := r.vertices[index]
+	return [4]float32{v.ColorR, v.ColorG, v.ColorB, v.ColorA}
+}
+
+// triangleColorAt returns a function that interpolates the given colors
+// barycentrically within the triangle, and extrapolates them outside.
+func triangleColorAt(p0, p1, p2 PointF32, c0, c1, c2 [4]float32) func(PointF32) [4]float32 {
+	e1, e2 := p1.Sub(p0), p2.Sub(p0)
+	det := cross2F32(e1, e2)
+	if det == 0 {
+		return func(PointF32) [4]float32 { return c0 }
+	}
+	return func(pt PointF32) [4]float32 {
+		v := pt.Sub(p0)
+		w1 := cross2F32(v, e2) / det
+		w2 := cross2F32(e1, v) / det
+		w0 := 1 - w1 - w2
+		var clr [4]float32
+		for i := range 4 {
+			clr[i] = min(max(w0*c0[i]+w1*c1[i]+w2*c2[i], 0), 1)
+		}
+		return clr
+	}
+}
+
+func cross2F32(a, b PointF32) float32 {
+	return a.X*b.Y - a.Y*b.X
+}
@@ -0,0 +1,106 @@
+package shapes
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestPolygonMesh(t *testing.T) {
+	polys := [][]PointF32{
+		{{10, 10}, {60, 15}, {30, 50}},
+		{{10, 10}, {30, 50}, {60, 15}}, // reversed winding
+		{{10, 10}, {90, 12}, {11, 14}}, // very sharp corner
+		{{10, 10}, {60, 10}, {60, 40}, {10, 40}},
+		{{10, 10}, {60, 20}, {55, 50}, {5, 30}},
+		{{10, 10}, {60, 10}, {60, 10}, {10, 40}}, // degenerate edge
+		{{20, 20}, {50, 30}, {50, 30}, {20, 20}}, // segment
+		{{30, 30}, {30, 30}, {30, 30}},           // point
+	}
+	white := func(PointF32) [4]float32 { return [4]float32{1, 1, 1, 1} }
+
+	var r Renderer
+	for i, poly := range polys {
+		for _, rounding := range []float32{0, 3, 8} {
+			extent := rounding + 2
+			vertices, indices := r.polygonMesh(0, 0, poly, rounding, extent, white)
+
+			// sample the interpolated SDF and compare it with the exact one
+			var wrong, uncovered int
+			for y := float32(-10); y < 110; y += 0.37 {
+				for x := float32(-10); x < 110; x += 0.41 {
+					pt := PointF32{X: x, Y: y}
+					want := polygonSDF(pt, poly) - rounding
+					covered := false
+					for k := 0; k < len(indices); k += 3 {
+						dist, inside := meshTriangleSDF(pt, vertices[indices[k]], vertices[indices[k+1]], vertices[indices[k+2]], len(poly))
+						if !inside {
+							continue
+						}
+						covered = true
+						if math.Abs(float64(dist-want)) > 2e-3 {
+							wrong += 1
+						}
+					}
+					if !covered && want < extent-rounding-0.01 {
+						uncovered += 1
+					}
+				}
+			}
+			if wrong > 0 || uncovered > 0 {
+				t.Errorf("poly #%d, rounding %v: %d wrong distances, %d uncovered samples", i, rounding, wrong, uncovered)
+			}
+		}
+	}
+}
+
+// meshTriangleSDF interpolates the polygon mesh attributes at the given point,
+// like the GPU would, and computes the same distance as the triangle and quad shaders.
+func meshTriangleSDF(pt PointF32, a, b, c ebiten.Vertex, numEdges int) (float32, bool) {
+	pa, pb, pc := PointF32{X: a.DstX, Y: a.DstY}, PointF32{X: b.DstX, Y: b.DstY}, PointF32{X: c.DstX, Y: c.DstY}
+	det := cross2F32(pb.Sub(pa), pc.Sub(pa))
+	if math.Abs(float64(det)) < 1e-9 {
+		return 0, false
+	}
+	w1 := cross2F32(pt.Sub(pa), pc.Sub(pa)) / det
+	w2 := cross2F32(pb.Sub(pa), pt.Sub(pa)) / det
+	w0 := 1 - w1 - w2
+	if w0 < -1e-5 || w1 < -1e-5 || w2 < -1e-5 {
+		return 0, false
+	}
+
+	lerp := func(va, vb, vc float32) float32 { return w0*va + w1*vb + w2*vc }
+	src := PointF32{X: lerp(a.SrcX, b.SrcX, c.SrcX), Y: lerp(a.SrcY, b.SrcY, c.SrcY)}
+	customs := [4]float32{
+		lerp(a.Custom0, b.Custom0, c.Custom0), lerp(a.Custom1, b.Custom1, c.Custom1),
+		lerp(a.Custom2, b.Custom2, c.Custom2), lerp(a.Custom3, b.Custom3, c.Custom3),
+	}
+	maxCustom := customs[0]
+	for _, custom := range customs[1:numEdges] {
+		maxCustom = max(maxCustom, custom)
+	}
+	return src.Length() + maxCustom, true
+}
+
+// polygonSDF returns the exact signed distance from the point to a convex polygon.
+func polygonSDF(pt PointF32, poly []PointF32) float32 {
+	minDist := float32(math.Inf(1))
+	var hasLeft, hasRight bool
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		edge, rel := b.Sub(a), pt.Sub(a)
+		h := float32(0)
+		if lenSq := edge.Dot(edge); lenSq > 0 {
+			h = min(max(rel.Dot(edge)/lenSq, 0), 1)
+		}
+		minDist = min(minDist, rel.Sub(edge.Scale(h)).Length())
+		side := cross2F32(edge, rel)
+		hasLeft = hasLeft || side > 0
+		hasRight = hasRight || side < 0
+	}
+	if hasLeft != hasRight {
+		return -minDist // inside
+	}
+	return minDist
+}
@@ -0,0 +1,375 @@
+package shapes
+
+import "math"
+
+// This file contains CPU-side descriptions of the main shapes, which can be
+// used for hit-testing. Distances are computed with the same signed distance
+// functions used by the shaders, so the results match the rendered shapes:
+// negative distances are inside the shape, and [Circle.Contains]() and similar
+// return true where the shaders would draw a non-zero alpha.
+//
+// Coordinates are continuous, so pixel (x, y) is sampled at (x + 0.5, y + 0.5).
+// Rounding follows the package conventions (see the package docs); neither
+// [Renderer.SetLegacyRounding]() nor [Renderer.SetPixelPerfect]() are considered.
+
+// Circle describes the shape drawn by [Renderer.DrawCircle]().
+type Circle struct {
+	CX, CY, Radius float32
+}
+
+// Distance returns the signed distance from (x, y) to the circle.
+func (c Circle) Distance(x, y float32) float32 {
+	return hypotF32(x-c.CX, y-c.CY) - c.Radius
+}
+
+// Contains returns whether (x, y) is within the circle.
+func (c Circle) Contains(x, y float32) bool {
+	return c.Distance(x, y) < 0
+}
+
+// Ring describes the shape drawn by [Renderer.DrawRing]().
+type Ring struct {
+	CX, CY, InRadius, OutRadius float32
+}
+
+// Distance returns the signed distance from (x, y) to the ring.
+func (ring Ring) Distance(x, y float32) float32 {
+	if ring.InRadius >= ring.OutRadius {
+		return Float32Inf()
+	}
+	dist := hypotF32(x-ring.CX, y-ring.CY)
+	return max(dist-ring.OutRadius, ring.InRadius-dist)
+}
+
+// Contains returns whether (x, y) is within the ring.
+func (ring Ring) Contains(x, y float32) bool {
+	return ring.Distance(x, y) < 0
+}
+
+// RingSector describes the shape drawn by [Renderer.DrawRingSector]().
+// See [RadsRight] constants for angle conventions and docs.
+type RingSector struct {
+	CX, CY, InRadius, OutRadius float32
+	StartRads, EndRads          float64
+	Rounding                    float32
+}
+
+// Distance returns the signed distance from (x, y) to the ring sector.
+func (sector RingSector) Distance(x, y float32) float32 {
+	if sector.InRadius >= sector.OutRadius || sector.OutRadius < 0 || sector.StartRads == sector.EndRads {
+		return Float32Inf()
+	}
+	if sector.InRadius <= 0 {
+		return Pie{sector.CX, sector.CY, sector.OutRadius, sector.StartRads, sector.EndRads, sector.Rounding}.Distance(x, y)
+	}
+	if sector.EndRads >= sector.StartRads+2*math.Pi {
+		return Ring{sector.CX, sector.CY, sector.InRadius, sector.OutRadius}.Distance(x, y)
+	}
+
+	startRads, endRads := normURads(sector.StartRads), normURads(sector.EndRads)
+	delta := uradsDeltaCW(startRads, endRads)
+	centerDir := uradsAddCW(startRads, delta/2.0)
+	inRadius, outRadius, apexShift, dilation := ringSectorRoundingParams(sector.InRadius, sector.OutRadius, delta/2.0, sector.Rounding)
+	ws, wc := math.Sincos(delta / 2.0)
+	px, py := rotateF64(float64(x-sector.CX), float64(y-sector.CY), -centerDir)
+	return float32(sdfRingSector(px, py, ws, wc, float64(inRadius), float64(outRadius), float64(apexShift))) - dilation
+}
+
+// Contains returns whether (x, y) is within the ring sector.
+func (sector RingSector) Contains(x, y float32) bool {
+	return sector.Distance(x, y) < 0
+}
+
+// Pie describes the shape drawn by [Renderer.DrawPie]().
+// See [RadsRight] constants for angle conventions and docs.
+type Pie struct {
+	CX, CY, Radius     float32
+	StartRads, EndRads float64
+	Rounding           float32
+}
+
+// Distance returns the signed distance from (x, y) to the pie.
+func (pie Pie) Distance(x, y float32) float32 {
+	if pie.StartRads == pie.EndRads || pie.Radius < 0 {
+		return Float32Inf()
+	}
+	if pie.EndRads >= pie.StartRads+2*math.Pi {
+		return Circle{pie.CX, pie.CY, pie.Radius}.Distance(x, y)
+	}
+
+	startRads, endRads := normURads(pie.StartRads), normURads(pie.EndRads)
+	delta := uradsDeltaCW(startRads, endRads)
+	centerDir := uradsAddCW(startRads, delta/2.0)
+	rate := delta / (2 * math.Pi)
+	pieRadius, apexShift, dilation, _ := pieRoundingParams(pie.Radius, rate, pie.Rounding)
+	ws, wc := math.Sincos(rate * math.Pi)
+	px, py := rotateF64(float64(x-pie.CX), float64(y-pie.CY), -centerDir)
+	var dist float64
+	if apexShift > 0 {
+		dist = sdfShiftedPie(px, py, ws, wc, float64(pieRadius), float64(apexShift))
+	} else {
+		dist = sdfPie(px, py, ws, wc, float64(pieRadius))
+	}
+	return float32(dist) - dilation
+}
+
+// Contains returns whether (x, y) is within the pie.
+func (pie Pie) Contains(x, y float32) bool {
+	return pie.Distance(x, y) < 0
+}
+
+// Area describes the shape drawn by [Renderer.DrawArea]().
+type Area struct {
+	OX, OY, W, H, Rounding float32
+}
+
+// Distance returns the signed distance from (x, y) to the area.
+func (area Area) Distance(x, y float32) float32 {
+	ox, oy, w, h := area.OX, area.OY, area.W, area.H
+	if w < 0 {
+		w = -w
+		ox -= w
+	}
+	if h < 0 {
+		h = -h
+		oy -= h
+	}
+	inRounding, outRounding := splitRounding(area.Rounding)
+	if outRounding > 0 {
+		ox, oy = ox-outRounding, oy-outRounding
+		w, h = w+outRounding*2, h+outRounding*2
+	}
+	rounding := min(inRounding, min(w, h)/2) + outRounding
+
+	// like the shader, but without the geometry clipping
+	// out of bounds distances
+	dx := abs(x-ox-w/2) - (w/2 - rounding)
+	dy := abs(y-oy-h/2) - (h/2 - rounding)
+	outDist := hypotF32(max(dx, 0), max(dy, 0))
+	inDist := min(max(dx, dy), 0)
+	return outDist + inDist - rounding
+}
+
+// Contains returns whether (x, y) is within the area.
+func (area Area) Contains(x, y float32) bool {
+	return area.Distance(x, y) < 0
+}
+
+// Triangle describes the shape drawn by [Renderer.DrawTriangle]().
+type Triangle struct {
+	X1, Y1, X2, Y2, X3, Y3 float64
+	Rounding               float64
+}
+
+// Distance returns the signed distance from (x, y) to the triangle.
+func (tri Triangle) Distance(x, y float32) float32 {
+	ox1, oy1, ox2, oy2, ox3, oy3 := tri.X1, tri.Y1, tri.X2, tri.Y2, tri.X3, tri.Y3
+	triArea := math.Abs((ox1*(oy2-oy3) + ox2*(oy3-oy1) + ox3*(oy1-oy2)) / 2)
+	if triArea < 1e-6 {
+		return Float32Inf()
+	}
+
+	inRounding, outRounding := splitRounding(float32(tri.Rounding))
+	if inRounding != 0 {
+		perimeter := math.Hypot(ox2-ox1, oy2-oy1) + math.Hypot(ox3-ox2, oy3-oy2) + math.Hypot(ox1-ox3, oy1-oy3)
+		inRadius := min(float64(inRounding), 2*triArea/perimeter)
+		inRounding = float32(inRadius)
+		ox1, oy1, ox2, oy2, ox3, oy3 = insetTriangle(ox1, oy1, ox2, oy2, ox3, oy3, inRadius)
+	}
+	dist := sdfTriangle(float64(x), float64(y), ox1, oy1, ox2, oy2, ox3, oy3)
+	return float32(dist) - (inRounding + outRounding)
+}
+
+// Contains returns whether (x, y) is within the triangle.
+func (tri Triangle) Contains(x, y float32) bool {
+	return tri.Distance(x, y) < 0
+}
+
+// Hexagon describes the shape drawn by [Renderer.DrawHexagon]().
+type Hexagon struct {
+	OX, OY, Radius, Rounding, Rads float32
+}
+
+// Distance returns the signed distance from (x, y) to the hexagon.
+func (hex Hexagon) Distance(x, y float32) float32 {
+	const apothemToRadiusFactor = 0.866025404 // math.Sqrt(3)/2
+	inRounding, outRounding := splitRounding(hex.Rounding)
+	apothem := float64((hex.Radius - inRounding) * apothemToRadiusFactor)
+
+	// see distanceToHexagon in hexagon.kage
+	const kx, ky, kz = -0.866025404, 0.5, 0.577350269
+	px, py := float64(x-hex.OX), float64(y-hex.OY)
+	if hex.Rads != 0 {
+		px, py = rotateF64(px, py, float64(hex.Rads))
+	}
+	px, py = math.Abs(px), math.Abs(py)
+	k := 2.0 * min(kx*px+ky*py, 0.0)
+	px, py = px-k*kx, py-k*ky
+	px -= min(max(px, -kz*apothem), kz*apothem)
+	py -= apothem
+	dist := math.Hypot(px, py) * sign(py)
+	return float32(dist) - (inRounding + outRounding)
+}
+
+// Contains returns whether (x, y) is within the hexagon.
+func (hex Hexagon) Contains(x, y float32) bool {
+	return hex.Distance(x, y) < 0
+}
+
+// Ellipse describes the shape drawn by [Renderer.DrawEllipse](). Like in the
+// shader, the distance is an approximation, but the sign is always exact.
+type Ellipse struct {
+	CX, CY, HorzRadius, VertRadius float32
+	Rads                           float64
+}
+
+// Distance returns the approximate signed distance from (x, y) to the ellipse.
+func (ellipse Ellipse) Distance(x, y float32) float32 {
+	rx, ry := float64(ellipse.HorzRadius), float64(ellipse.VertRadius)
+	px, py := float64(x-ellipse.CX), float64(y-ellipse.CY)
+	if ellipse.Rads != 0 {
+		px, py = rotateF64(px, py, ellipse.Rads)
+	}
+	if px == 0 && py == 0 {
+		return -float32(min(rx, ry))
+	}
+	k1 := math.Hypot(px/rx, py/ry)
+	k2 := math.Hypot(px/(rx*rx), py/(ry*ry))
+	return float32(k1 * (k1 - 1.0) / k2)
+}
+
+// Contains returns whether (x, y) is within the ellipse.
+func (ellipse Ellipse) Contains(x, y float32) bool {
+	return ellipse.Distance(x, y) < 0
+}
+
+// Quad describes the shape drawn by [Renderer.DrawQuad](). Points must be
+// given in clockwise order starting from top-left.
+type Quad struct {
+	Points   [4]PointF32
+	Rounding float32
+}
+
+// Distance returns the signed distance from (x, y) to the quad.
+func (quad Quad) Distance(x, y float32) float32 {
+	shape, thickening := quad.Points, quad.Rounding
+	if thickening < 0 {
+		shape, thickening = expandQuad(quad.Points, thickening), -thickening
+	}
+
+	// see distanceToQuadThick in quad.kage. unlike the shader, the inside
+	// test is always required, as there's no geometry clipping
+	p := PointF32{X: x, Y: y}
+	minDistSq := Float32Inf()
+	inside := true
+	for i := range 4 {
+		a, b := shape[i], shape[(i+1)%4]
+		ba, pa := b.Sub(a), p.Sub(a)
+		h := min(max((pa.X*ba.X+pa.Y*ba.Y)/(ba.X*ba.X+ba.Y*ba.Y), 0), 1)
+		sx, sy := pa.X-h*ba.X, pa.Y-h*ba.Y
+		minDistSq = min(minDistSq, sx*sx+sy*sy)
+		if ba.X*pa.Y-ba.Y*pa.X < 0 {
+			inside = false
+		}
+	}
+	dist := float32(math.Sqrt(float64(minDistSq)))
+	if inside {
+		return -dist - thickening
+	}
+	return dist - thickening
+}
+
+// Contains returns whether (x, y) is within the quad.
+func (quad Quad) Contains(x, y float32) bool {
+	return quad.Distance(x, y) < 0
+}
+
+func hypotF32(x, y float32) float32 {
+	return float32(math.Hypot(float64(x), float64(y)))
+}
+
+// rotateF64 is the equivalent of rotate() in the shaders.
+func rotateF64(x, y, rads float64) (float64, float64) {
+	sinR, cosR := math.Sincos(rads)
+	return x*cosR - y*sinR, x*sinR + y*cosR
+}
+
+// sdfRingSector is the CPU version of sdfRingSector in ring_sector.kage.
+func sdfRingSector(px, py, ws, wc, inRadius, outRadius, apexShift float64) float64 {
+	px, py = math.Abs(py), px // switch symmetry axis
+	lenPos := math.Hypot(px, py)
+	l := max(lenPos-outRadius, inRadius-lenPos)
+
+	b := apexShift * wc
+	c := apexShift*apexShift - b*b
+	uIn := max(-b+math.Sqrt(max(inRadius*inRadius-c, 0.0)), 0.0)
+	uOut := max(-b+math.Sqrt(max(outRadius*outRadius-c, 0.0)), uIn)
+	pay := py - apexShift
+	t := min(max(px*ws+pay*wc, uIn), uOut)
+	m := math.Hypot(px-ws*t, pay-wc*t)
+	m *= sign(wc*px - ws*pay)
+	return max(l, m)
+}
+
+// sdfPie is the CPU version of sdfPie in pie.kage.
+func sdfPie(px, py, ws, wc, radius float64) float64 {
+	px, py = math.Abs(py), px // switch symmetry axis
+	l := math.Hypot(px, py) - radius
+	t := min(max(px*ws+py*wc, 0.0), radius)
+	m := math.Hypot(px-ws*t, py-wc*t)
+	return max(l, m*sign(wc*px-ws*py))
+}
+
+// sdfShiftedPie is the CPU version of sdfShiftedPie in pie.kage.
+func sdfShiftedPie(px, py, ws, wc, radius, apexShift float64) float64 {
+	py = math.Abs(py)
+	dirX, dirY := wc, ws
+
+	b := apexShift * dirX
+	u := -b + math.Sqrt(max(b*b-apexShift*apexShift+radius*radius, 0.0))
+	edgeX, edgeY := apexShift+dirX*u, dirY*u
+	pax, pay := px-apexShift, py
+	t := min(max(pax*dirX+pay*dirY, 0.0), u)
+	dist := math.Hypot(pax-dirX*t, pay-dirY*t)
+
+	lenPos := math.Hypot(px, py)
+	if px*edgeY-py*edgeX >= 0 {
+		dist = min(dist, math.Abs(lenPos-radius))
+	}
+	if lenPos <= radius && pax*dirY-pay*dirX >= 0 {
+		return -dist
+	}
+	return dist
+}
+
+// sdfTriangle is the CPU version of distanceToTriangle in triangle.kage.
+func sdfTriangle(x, y, x0, y0, x1, y1, x2, y2 float64) float64 {
+	e0x, e0y := x1-x0, y1-y0
+	e1x, e1y := x2-x1, y2-y1
+	e2x, e2y := x0-x2, y0-y2
+	v0x, v0y := x-x0, y-y0
+	v1x, v1y := x-x1, y-y1
+	v2x, v2y := x-x2, y-y2
+	h0 := min(max((v0x*e0x+v0y*e0y)/(e0x*e0x+e0y*e0y), 0), 1)
+	h1 := min(max((v1x*e1x+v1y*e1y)/(e1x*e1x+e1y*e1y), 0), 1)
+	h2 := min(max((v2x*e2x+v2y*e2y)/(e2x*e2x+e2y*e2y), 0), 1)
+	pq0x, pq0y := v0x-e0x*h0, v0y-e0y*h0
+	pq1x, pq1y := v1x-e1x*h1, v1y-e1y*h1
+	pq2x, pq2y := v2x-e2x*h2, v2y-e2y*h2
+	s := sign(e0x*e2y - e0y*e2x)
+	distSq := min(pq0x*pq0x+pq0y*pq0y, pq1x*pq1x+pq1y*pq1y, pq2x*pq2x+pq2y*pq2y)
+	side := min(s*(v0x*e0y-v0y*e0x), s*(v1x*e1y-v1y*e1x), s*(v2x*e2y-v2y*e2x))
+	return -math.Sqrt(distSq) * sign(side)
+}
+
+// sign is the equivalent of sign() in the shaders.
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
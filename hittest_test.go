@@ -0,0 +1,140 @@
+package shapes
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestHitTesting(t *testing.T) {
+	type hitTester interface {
+		Contains(x, y float32) bool
+	}
+	quad := [4]PointF32{{X: 10, Y: 10}, {X: 90, Y: 20}, {X: 80, Y: 80}, {X: 20, Y: 70}}
+	tests := []struct {
+		name  string
+		shape hitTester
+		x, y  float32
+		want  bool
+	}{
+		{"circle/center", Circle{50, 50, 20}, 50, 50, true},
+		{"circle/edge", Circle{50, 50, 20}, 69.9, 50, true},
+		{"circle/out", Circle{50, 50, 20}, 70.1, 50, false},
+		{"ring/hole", Ring{50, 50, 10, 20}, 50, 55, false},
+		{"ring/in", Ring{50, 50, 10, 20}, 50, 65, true},
+		{"ring/empty", Ring{50, 50, 20, 20}, 50, 70, false},
+		{"sector/top", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, 0}, 100, 40, true},
+		{"sector/bottom", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, 0}, 100, 160, false},
+		{"sector/side", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, 0}, 160, 100, false},
+		{"sector/hole", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, 0}, 100, 70, false},
+		{"sector/wrap", RingSector{100, 100, 40, 80, RadsTopRight, RadsBottomRight, 0}, 160, 100, true},
+		{"sector/rounded", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, 4}, 100, 37, true},
+		{"sector/inner-rounding-corner", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, -8}, 62.8, 30.3, false},
+		{"sector/inner-rounding-side", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, -8}, 72.3, 46.8, true},
+		{"sector/no-hole", RingSector{100, 100, 0, 80, RadsTop - 0.5, RadsTop + 0.5, 0}, 100, 90, true},
+		{"sector/no-hole-side", RingSector{100, 100, 0, 80, RadsTop - 0.5, RadsTop + 0.5, 0}, 100, 160, false},
+		{"sector/inner-rounding-top", RingSector{100, 100, 40, 80, RadsTop - 0.5, RadsTop + 0.5, -8}, 100, 20.5, true},
+		{"pie/in", Pie{50, 50, 40, RadsRight, RadsBottom, 0}, 70, 70, true},
+		{"pie/out", Pie{50, 50, 40, RadsRight, RadsBottom, 0}, 30, 70, false},
+		{"pie/full", Pie{50, 50, 40, RadsRight, RadsRight + 2*math.Pi, 0}, 30, 30, true},
+		{"pie/inner-rounding", Pie{50, 50, 40, RadsRight, RadsBottom, -8}, 50.5, 50.5, false},
+		{"pie/outer-rounding", Pie{50, 50, 40, RadsRight, RadsBottom, 8}, 46, 46, true},
+		{"area/in", Area{10, 10, 40, 30, 0}, 11, 11, true},
+		{"area/negative-size", Area{50, 40, -40, -30, 0}, 11, 11, true},
+		{"area/inner-rounding", Area{10, 10, 40, 30, -8}, 11, 11, false},
+		{"area/outer-rounding", Area{10, 10, 40, 30, 8}, 5, 20, true},
+		{"area/outer-corner", Area{10, 10, 40, 30, 8}, 3, 3, false},
+		{"triangle/in", Triangle{10, 60, 50, 10, 90, 60, 0}, 50, 40, true},
+		{"triangle/out", Triangle{10, 60, 50, 10, 90, 60, 0}, 20, 20, false},
+		{"triangle/inner-rounding", Triangle{10, 60, 50, 10, 90, 60, -8}, 50, 11, false},
+		{"triangle/outer-rounding", Triangle{10, 60, 50, 10, 90, 60, 8}, 50, 64, true},
+		{"hexagon/center", Hexagon{50, 50, 30, 0, 0}, 50, 50, true},
+		{"hexagon/vertex", Hexagon{50, 50, 30, 0, 0}, 21, 50, true},
+		{"hexagon/flat-side", Hexagon{50, 50, 30, 0, 0}, 50, 22, false},
+		{"hexagon/rotated", Hexagon{50, 50, 30, 0, math.Pi / 2}, 50, 21, true},
+		{"ellipse/center", Ellipse{50, 50, 40, 10, 0}, 50, 50, true},
+		{"ellipse/horz", Ellipse{50, 50, 40, 10, 0}, 85, 50, true},
+		{"ellipse/vert", Ellipse{50, 50, 40, 10, 0}, 50, 65, false},
+		{"ellipse/rotated", Ellipse{50, 50, 40, 10, math.Pi / 2}, 50, 85, true},
+		{"quad/in", Quad{quad, 0}, 50, 50, true},
+		{"quad/out", Quad{quad, 0}, 85, 85, false},
+		{"quad/outer-rounding", Quad{quad, 6}, 50, 12, true},
+		{"quad/inner-rounding", Quad{quad, -6}, 11, 11, false},
+	}
+	for _, test := range tests {
+		if got := test.shape.Contains(test.x, test.y); got != test.want {
+			t.Errorf("%s: Contains(%v, %v) = %v, want %v", test.name, test.x, test.y, got, test.want)
+		}
+	}
+}
+
+// go test -run ^TestHitTestingMatchesShaders$ . -count 1
+func TestHitTestingMatchesShaders(t *testing.T) {
+	const size = 128
+	quad := [4]PointF32{{X: 14, Y: 12}, {X: 110, Y: 24}, {X: 100, Y: 116}, {X: 20, Y: 96}}
+	cases := []struct {
+		name  string
+		shape interface{ Distance(x, y float32) float32 }
+		draw  func(r *Renderer, target *ebiten.Image)
+	}{
+		{"circle", Circle{64, 64, 40.3}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawCircle(target, 64, 64, 40.3)
+		}},
+		{"ring", Ring{64, 64, 20, 50}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawRing(target, 64, 64, 20, 50)
+		}},
+		{"ring sector", RingSector{64, 64, 24, 56, RadsTop - 0.7, RadsRight + 0.2, -3}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawRingSector(target, 64, 64, 24, 56, RadsTop-0.7, RadsRight+0.2, -3)
+		}},
+		{"ring sector without hole", RingSector{64, 64, 0, 56, RadsTop - 0.7, RadsRight + 0.2, 4}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawRingSector(target, 64, 64, 0, 56, RadsTop-0.7, RadsRight+0.2, 4)
+		}},
+		{"pie", Pie{64, 64, 56, RadsBottomLeft, RadsTopRight, 5}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawPie(target, 64, 64, 56, RadsBottomLeft, RadsTopRight, 5)
+		}},
+		{"pie inner rounding", Pie{64, 64, 56, RadsRight, RadsBottomLeft, -9}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawPie(target, 64, 64, 56, RadsRight, RadsBottomLeft, -9)
+		}},
+		{"area", Area{16, 24, 90, 70, -14}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawArea(target, 16, 24, 90, 70, -14)
+		}},
+		{"triangle", Triangle{12, 110, 60, 10, 116, 96, -10}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawTriangle(target, 12, 110, 60, 10, 116, 96, -10)
+		}},
+		{"hexagon", Hexagon{64, 64, 50, 4, 0.3}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawHexagon(target, 64, 64, 50, 4, 0.3)
+		}},
+		{"ellipse", Ellipse{64, 64, 56, 28, 0.6}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawEllipse(target, 64, 64, 56, 28, 0.6)
+		}},
+		{"quad", Quad{quad, -8}, func(r *Renderer, target *ebiten.Image) {
+			r.DrawQuad(target, quad, -8)
+		}},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		img := ebiten.NewImage(size, size)
+		pix := make([]byte, size*size*4)
+		for _, c := range cases {
+			img.Clear()
+			c.draw(r, img)
+			img.ReadPixels(pix)
+		pixels:
+			for y := range size {
+				for x := range size {
+					dist := c.shape.Distance(float32(x)+0.5, float32(y)+0.5)
+					if abs(dist) < 0.05 {
+						continue // too close to the edge for float precision
+					}
+					drawn := pix[(y*size+x)*4+3] > 0
+					if drawn != (dist < 0) {
+						fail("%s: hit-testing doesn't match the rendered shape", c.name)
+						break pixels
+					}
+				}
+			}
+		}
+	})
+}
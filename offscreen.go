@@ -14,6 +14,11 @@ type offscreen struct {
 	maxWidth    int // none if <= 0
 	maxHeight   int // none if <= 0
 	extraMargin int
+
+	// usage tracking for shrinking, see OffscreenPool.NextFrame()
+	frameWidth   int // max requested width during the current frame
+	frameHeight  int // max requested height during the current frame
+	lowUseFrames int
 }
 
 // maxWidth and maxHeight <= 0 indicate no size limit
@@ -37,6 +42,7 @@ func (off *offscreen) WithSize(w, h int, clear bool) *ebiten.Image {
 		panic(fmt.Sprintf("requested offscreen of size %dx%d, but maxWidth/maxHeight are %dx%d", w, h, off.maxWidth, off.maxHeight))
 	}
 
+	off.frameWidth, off.frameHeight = max(off.frameWidth, w), max(off.frameHeight, h)
 	nw, nh := w+off.extraMargin, h+off.extraMargin
 	if hasSizeLimits {
 		nw, nh = min(nw, off.maxWidth), min(nh, off.maxHeight)
@@ -70,6 +76,39 @@ func (off *offscreen) WithSize(w, h int, clear bool) *ebiten.Image {
 	return off.image
 }
 
+// lowUse returns whether the sizes requested during the current frame, including
+// the extra margin, would fit in less than half of the current offscreen pixels.
+func (off *offscreen) lowUse() bool {
+	if off.parent == nil {
+		return false
+	}
+	var usedPixels int
+	if off.frameWidth > 0 && off.frameHeight > 0 {
+		usedPixels = (off.frameWidth + off.extraMargin) * (off.frameHeight + off.extraMargin)
+	}
+	bounds := off.parent.Bounds()
+	return usedPixels*2 < bounds.Dx()*bounds.Dy()
+}
+
+// release deallocates the offscreen, which will be allocated
+// again at the requested size on the next WithSize() call.
+func (off *offscreen) release() {
+	if off.parent != nil {
+		off.parent.Deallocate()
+	}
+	off.parent, off.image = nil, nil
+	off.lowUseFrames = 0
+}
+
+// fits returns whether the offscreen can be resized to w x h without reallocating.
+func (off *offscreen) fits(w, h int) bool {
+	if off.parent == nil {
+		return false
+	}
+	bounds := off.parent.Bounds()
+	return bounds.Dx() >= w && bounds.Dy() >= h
+}
+
 func (off *offscreen) clearParentFor(w, h int) {
 	bounds := off.parent.Bounds()
 	currWidth, currHeight := bounds.Dx(), bounds.Dy()
@@ -0,0 +1,225 @@
+package shapes
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// OffscreenPool manages offscreens for one or more renderers. It holds the internal
+// offscreens that renderer functions use for multi-pass effects, and it can also lease
+// offscreens to the user through [OffscreenPool.Lease](). Leased offscreens are never
+// shared with the internal ones, so they can be safely passed to any renderer function.
+//
+// Each renderer creates its own pool by default, but a pool can be shared between many
+// renderers with [Renderer.SetOffscreenPool]() to reduce memory usage. Renderers are not
+// concurrency-safe, and neither are pools, so they must all be used from the same goroutine.
+type OffscreenPool struct {
+	temps  []offscreen // internal offscreens, by index
+	leases []leaseSlot
+	memory int // bytes, see Stats.OffscreenMemory
+
+	shrinkFrames int // 0 if disabled
+	budget       int // bytes, 0 if disabled
+	onExceeded   func(memory, budget int)
+}
+
+type leaseSlot struct {
+	offscreen offscreen
+	leased    bool
+}
+
+// NewOffscreenPool creates a new empty pool. Offscreens are created on demand.
+func NewOffscreenPool() *OffscreenPool {
+	return &OffscreenPool{}
+}
+
+// Lease returns an offscreen of the given size that can be used until it's released.
+// If clear is true, the offscreen is cleared, including 1 extra pixel of clear padding
+// to prevent problems with bleeding edges. Released offscreens are reused by later
+// leases, so the lease image must not be used or stored after [OffscreenLease.Release]().
+func (pool *OffscreenPool) Lease(w, h int, clear bool) *OffscreenLease {
+	// prefer free offscreens that don't need to grow
+	slotIndex := -1
+	for i := range pool.leases {
+		slot := &pool.leases[i]
+		if slot.leased {
+			continue
+		}
+		if slotIndex == -1 || slot.offscreen.fits(w, h) {
+			slotIndex = i
+		}
+		if slot.offscreen.fits(w, h) {
+			break
+		}
+	}
+	if slotIndex == -1 {
+		slotIndex = len(pool.leases)
+		pool.leases = append(pool.leases, leaseSlot{offscreen: newOffscreen(0, 0, 64)})
+	}
+
+	slot := &pool.leases[slotIndex]
+	slot.leased = true
+	image, _ := pool.withSize(&slot.offscreen, w, h, clear)
+	return &OffscreenLease{pool: pool, slot: slotIndex, image: image}
+}
+
+// Leases returns the number of active leases.
+func (pool *OffscreenPool) Leases() int {
+	var count int
+	for _, slot := range pool.leases {
+		if slot.leased {
+			count += 1
+		}
+	}
+	return count
+}
+
+// ReleaseTemps releases all the offscreens in the pool, except active leases.
+// Offscreens are allocated again when needed, so this can be used to free memory
+// after a scene that required big offscreens, like a loading screen with a big
+// blur. See also [OffscreenPool.SetShrinkPolicy]().
+func (pool *OffscreenPool) ReleaseTemps() {
+	for i := range pool.temps {
+		pool.release(&pool.temps[i])
+	}
+	for i := range pool.leases {
+		if !pool.leases[i].leased {
+			pool.release(&pool.leases[i].offscreen)
+		}
+	}
+}
+
+// SetShrinkPolicy makes the pool release offscreens that have been used below half
+// of their size for the given number of consecutive frames, so they can be allocated
+// again at a smaller size the next time they are needed. Frames are delimited by
+// [OffscreenPool.NextFrame](), which must be called once per frame for the policy
+// to work. Zero disables the policy, which is the default.
+//
+// The function panics if frames is negative.
+func (pool *OffscreenPool) SetShrinkPolicy(frames int) {
+	if frames < 0 {
+		panic("frames < 0")
+	}
+	pool.shrinkFrames = frames
+}
+
+// NextFrame must be called at the start or end of each frame when using a shrink
+// policy. If the pool is shared by multiple renderers, it must still be called only
+// once per frame. See [OffscreenPool.SetShrinkPolicy]().
+func (pool *OffscreenPool) NextFrame() {
+	for i := range pool.temps {
+		pool.shrinkStep(&pool.temps[i])
+	}
+	for i := range pool.leases {
+		if !pool.leases[i].leased {
+			pool.shrinkStep(&pool.leases[i].offscreen)
+		}
+	}
+}
+
+// SetMemoryBudget sets the maximum memory that the pool offscreens should use, in bytes.
+// When an allocation exceeds the budget, onExceeded is called with the current memory
+// usage and the budget, or the function panics if onExceeded is nil. A budget <= 0
+// disables the check, which is the default. See also [Stats].OffscreenMemory.
+func (pool *OffscreenPool) SetMemoryBudget(budget int, onExceeded func(memory, budget int)) {
+	pool.budget = max(budget, 0)
+	pool.onExceeded = onExceeded
+}
+
+// shrinkStep updates the low use frames count of the offscreen
+// at the end of a frame, releasing it if it has been under used
+// for too long.
+func (pool *OffscreenPool) shrinkStep(off *offscreen) {
+	if pool.shrinkFrames > 0 {
+		if off.lowUse() {
+			off.lowUseFrames += 1
+			if off.lowUseFrames >= pool.shrinkFrames {
+				pool.release(off)
+			}
+		} else {
+			off.lowUseFrames = 0
+		}
+	}
+	off.frameWidth, off.frameHeight = 0, 0
+}
+
+// release releases the offscreen and updates the pool memory usage.
+func (pool *OffscreenPool) release(off *offscreen) {
+	if off.parent != nil {
+		pool.memory -= imageMemory(off.parent)
+	}
+	off.release()
+}
+
+// temp returns the internal offscreen with the given index, resized
+// to w x h, and the image that backed the offscreen before the call.
+func (pool *OffscreenPool) temp(offscreenIndex int, w, h int, clear bool) (*ebiten.Image, *ebiten.Image) {
+	for offscreenIndex >= len(pool.temps) {
+		pool.temps = append(pool.temps, newOffscreen(0, 0, 64))
+	}
+	return pool.withSize(&pool.temps[offscreenIndex], w, h, clear)
+}
+
+// withSize calls [offscreen.WithSize]() and updates the pool memory usage.
+// Returns the resized image and the image that backed the offscreen before
+// the call, which can be compared with off.parent to detect allocations.
+func (pool *OffscreenPool) withSize(off *offscreen, w, h int, clear bool) (*ebiten.Image, *ebiten.Image) {
+	prevParent := off.parent
+	image := off.WithSize(w, h, clear)
+	if off.parent != prevParent {
+		if prevParent != nil {
+			pool.memory -= imageMemory(prevParent)
+		}
+		pool.memory += imageMemory(off.parent)
+		if pool.budget > 0 && pool.memory > pool.budget {
+			if pool.onExceeded == nil {
+				panic(fmt.Sprintf("offscreen memory budget exceeded (%d > %d bytes)", pool.memory, pool.budget))
+			}
+			pool.onExceeded(pool.memory, pool.budget)
+		}
+	}
+	return image, prevParent
+}
+
+// checkTempAliasing panics if any of the given images is the current
+// image of the internal offscreen with the given index. Only subimages
+// returned by [Renderer.UnsafeTemp]() and similar can be detected, not
+// subimages of them. The check only runs in debug builds (shapesdebug
+// build tag).
+func (pool *OffscreenPool) checkTempAliasing(offscreenIndex int, images ...*ebiten.Image) {
+	if !debugChecks || offscreenIndex >= len(pool.temps) {
+		return
+	}
+	tempImage := pool.temps[offscreenIndex].image
+	for _, image := range images {
+		if image != nil && image == tempImage {
+			panic(fmt.Sprintf("image aliases internal offscreen #%d, which the function is about to overwrite", offscreenIndex))
+		}
+	}
+}
+
+// OffscreenLease is an offscreen leased from an [OffscreenPool].
+type OffscreenLease struct {
+	pool  *OffscreenPool
+	slot  int
+	image *ebiten.Image
+}
+
+// Image returns the leased offscreen. The function panics if the lease has been released.
+func (lease *OffscreenLease) Image() *ebiten.Image {
+	if lease.image == nil {
+		panic("offscreen lease already released")
+	}
+	return lease.image
+}
+
+// Release returns the offscreen to the pool. The function panics if the lease has already
+// been released.
+func (lease *OffscreenLease) Release() {
+	if lease.image == nil {
+		panic("offscreen lease already released")
+	}
+	lease.pool.leases[lease.slot].leased = false
+	lease.image = nil
+}
@@ -0,0 +1,144 @@
+package shapes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// go test -run ^TestOffscreenPool$ . -count 1
+func TestOffscreenPool(t *testing.T) {
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		expectPanic := func(name string, fn func()) {
+			defer func() {
+				if recover() == nil {
+					fail("%s: expected panic", name)
+				}
+			}()
+			fn()
+		}
+
+		pool := NewOffscreenPool()
+		a, b := pool.Lease(32, 32, true), pool.Lease(16, 16, false)
+		if a.Image() == b.Image() {
+			fail("active leases share the same image")
+		}
+		if bounds := b.Image().Bounds(); bounds.Dx() != 16 || bounds.Dy() != 16 {
+			fail("unexpected lease size")
+		}
+		if pool.Leases() != 2 {
+			fail("expected 2 active leases")
+		}
+
+		// released offscreens are reused
+		memory := pool.memory
+		a.Release()
+		c := pool.Lease(20, 20, true)
+		if pool.memory != memory || pool.Leases() != 2 {
+			fail("released offscreen not reused")
+		}
+		expectPanic("image after release", func() { a.Image() })
+		expectPanic("double release", a.Release)
+		b.Release()
+		c.Release()
+
+		// renderers sharing a pool
+		r1, r2 := NewRenderer(), NewRenderer()
+		r1.SetOffscreenPool(pool)
+		r2.SetOffscreenPool(pool)
+		temp := r1.UnsafeTemp(0, 40, 40)
+		if r2.UnsafeTemp(0, 40, 40) != temp {
+			fail("renderers sharing a pool got different internal offscreens")
+		}
+		if r1.Stats().OffscreenAllocs != 1 || r2.Stats().OffscreenAllocs != 0 {
+			fail("unexpected offscreen allocs for shared pool")
+		}
+		if r2.Stats().OffscreenMemory != pool.memory {
+			fail("shared pool memory not reported")
+		}
+		leased := pool.Lease(40, 40, false)
+		if leased.Image() == temp {
+			fail("lease aliases an internal offscreen")
+		}
+		leased.Release()
+	})
+}
+
+// go test -run ^TestOffscreenPoolMemory$ . -count 1
+func TestOffscreenPoolMemory(t *testing.T) {
+	const margin = 64 // extra margin of internal offscreens
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		expectMemory := func(name string, pool *OffscreenPool, want int) {
+			if pool.memory != want {
+				fail("%s: got %d bytes, want %d", name, pool.memory, want)
+			}
+		}
+
+		r := NewRenderer()
+		pool := r.OffscreenPool()
+
+		// release, keeping active leases
+		r.UnsafeTemp(0, 256, 256)
+		lease := pool.Lease(16, 16, false)
+		r.ReleaseTemps()
+		expectMemory("release", pool, (16+margin)*(16+margin)*4)
+		lease.Release()
+		r.ReleaseTemps()
+		expectMemory("release all", pool, 0)
+
+		// shrink after 3 frames of low use
+		pool.SetShrinkPolicy(3)
+		r.UnsafeTemp(0, 256, 256)
+		pool.NextFrame()
+		for frame := range 3 {
+			expectMemory(fmt.Sprintf("frame %d", frame), pool, (256+margin)*(256+margin)*4)
+			r.UnsafeTemp(0, 16, 16)
+			pool.NextFrame()
+		}
+		expectMemory("shrink", pool, 0)
+		r.UnsafeTemp(0, 16, 16)
+		expectMemory("shrunk", pool, (16+margin)*(16+margin)*4)
+		pool.NextFrame() // in use, not released
+		expectMemory("shrunk in use", pool, (16+margin)*(16+margin)*4)
+
+		// budgets
+		var reported int
+		pool.SetMemoryBudget(128*128*4, func(memory, budget int) { reported = memory })
+		lease = pool.Lease(100, 100, false)
+		if reported != pool.memory {
+			fail("exceeded budget not reported")
+		}
+		lease.Release()
+		r.ReleaseTemps()
+		pool.SetMemoryBudget(128*128*4, nil)
+		func() {
+			defer func() {
+				if recover() == nil {
+					fail("exceeded budget didn't panic")
+				}
+			}()
+			r.UnsafeTemp(0, 100, 100)
+		}()
+	})
+}
+
+// go test -tags shapesdebug -run ^TestTempAliasing$ . -count 1
+func TestTempAliasing(t *testing.T) {
+	if !debugChecks {
+		t.Skip("aliasing checks require the shapesdebug build tag")
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		defer func() {
+			if recover() == nil {
+				fail("passing internal offscreen #0 to ApplyBlur2 didn't panic")
+			}
+		}()
+		r := NewRenderer()
+		target := ebiten.NewImage(64, 64)
+		mask := r.UnsafeTempClear(0, 32, 32)
+		r.ApplyBlur2(target, mask, 0, 0, 4, 0)
+	})
+}
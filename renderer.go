@@ -1,7 +1,9 @@
 package shapes
 
 import (
+	"image"
 	"image/color"
+	"math"
 	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -14,10 +16,42 @@ type Renderer struct {
 	indices  []uint16
 	opts     ebiten.DrawTrianglesShaderOptions
 
-	singleClr     bool
-	strokeIndices []uint16
+	singleClr      bool
+	legacyRounding bool
+	pixelPerfect   bool
+	softEdge       float32
+	sourceClamping Clamping
+	strokeIndices  []uint16
 
-	temps []offscreen
+	// scratch geometry for draws that need more than the 4 base
+	// vertices without overwriting the renderer's vertex colors
+	auxVertices []ebiten.Vertex
+	auxIndices  []uint16
+	auxPoints   []PointF32
+	auxWidths   []float32
+
+	sdfKey []byte // scratch shader cache key, see DrawSDF()
+
+	pool       *OffscreenPool
+	stats      statsTracker
+	stateStack []rendererState
+
+	// transform, see SetGeoM()
+	geoM         ebiten.GeoM
+	geoMElems    [6]float32 // a, b, tx, c, d, ty
+	geoMScale    float32
+	hasGeoM      bool
+	geoMVertices []ebiten.Vertex
+
+	// clipping, see SetClipRect()
+	clipRect     image.Rectangle
+	hasClip      bool
+	clipVertices []ebiten.Vertex
+	clipIndices  []uint16
+	clipRemap    []int32
+	clipLayers   []clipLayer // see BeginClip()
+
+	internalPasses int // > 0 while drawing to internal offscreens
 }
 
 func NewRenderer() *Renderer {
@@ -26,6 +60,8 @@ func NewRenderer() *Renderer {
 	renderer.SetColor(color.RGBA{255, 255, 255, 255})
 	renderer.indices = []uint16{0, 1, 2, 0, 2, 3}
 	renderer.opts.Uniforms = make(map[string]any, 8)
+	renderer.softEdge = AAMargin
+	renderer.pool = NewOffscreenPool()
 	renderer.strokeIndices = []uint16{
 		0, 1, 4,
 		4, 1, 5,
@@ -80,6 +116,166 @@ func (r *Renderer) SetBlend(blend ebiten.Blend) {
 	r.opts.Blend = blend
 }
 
+// SetLegacyRounding can be used to restore the rounding behavior of older versions of
+// the package, where positive rounding values in [Renderer.DrawArea](), [Renderer.DrawTriangle](),
+// [Renderer.DrawHexagon]() and [Renderer.DrawPie]() (and their variants) did inner rounding
+// instead of expanding the shapes. This is only intended to help migrating old code, and
+// will be removed in the future. See the package docs for the current rounding conventions.
+func (r *Renderer) SetLegacyRounding(legacy bool) {
+	r.legacyRounding = legacy
+}
+
+// SetSoftEdge sets the antialiasing width, in pixels, used by the shape drawing
+// functions (circles, rects, rings, pies, triangles, lines, SDFs...). The default
+// is [AAMargin]. Zero makes the edges hard and aliased, while higher values can be
+// used for feathering or blurry shapes. Notice that the soft edge is applied inside
+// the shapes, so shapes don't grow with bigger values, but small shapes will fade.
+//
+// The function panics if softEdge < 0.
+func (r *Renderer) SetSoftEdge(softEdge float32) {
+	if softEdge < 0 {
+		panic("softEdge < 0")
+	}
+	r.softEdge = softEdge
+}
+
+// GetSoftEdge returns the antialiasing width set with [Renderer.SetSoftEdge]().
+func (r *Renderer) GetSoftEdge() float32 {
+	return r.softEdge
+}
+
+// SetPixelPerfect enables or disables the pixel-perfect mode, meant for low resolution
+// pixel art. In this mode, all shapes are drawn aliased, with no partial alpha, ignoring
+// [Renderer.SetSoftEdge](). Additionally, [Renderer.DrawCircle](), [Renderer.DrawRing](),
+// [Renderer.DrawEllipse](), [Renderer.DrawArea](), [Renderer.DrawLine]() and
+// [Renderer.DrawTriangle]() snap their parameters to the pixel grid so the resulting
+// shapes are symmetric and independent of subpixel positioning:
+//   - Circle, ring and ellipse centers are moved to the center of the pixel containing
+//     them, and radiuses are rounded to integers. This produces midpoint-circle-like
+//     results, with diameters of 2*radius + 1 pixels.
+//   - Area coordinates, sizes and rounding values are rounded to integers.
+//   - Line thicknesses are rounded to integers (min 1), and line ends are moved to
+//     pixel centers for odd thicknesses, or rounded to integer coordinates otherwise.
+//   - Triangle vertices, thicknesses and rounding values are rounded to integers.
+func (r *Renderer) SetPixelPerfect(enabled bool) {
+	r.pixelPerfect = enabled
+}
+
+// SetSourceClamping sets the sides on which effects clamp their sampling coordinates
+// to the source bounds, extending the edge pixels instead of treating pixels outside
+// the source as transparent. The default is [ClampNone]. Clamped sides don't get any
+// effect margins, so effects stay within the source bounds on those sides.
+//
+// This applies to all Apply* functions taking a mask except the downscaling D4 variants,
+// and to the mask of [Renderer.MaskAt](). Shadow functions combine these flags with their
+// own clamping argument.
+func (r *Renderer) SetSourceClamping(clamping Clamping) {
+	r.sourceClamping = clamping
+}
+
+// clampMargins returns the given margin for each side, or zero for
+// the sides clamped with [Renderer.SetSourceClamping]().
+func (r *Renderer) clampMargins(margin float32) (left, top, right, bottom float32) {
+	left, top, right, bottom = margin, margin, margin, margin
+	if r.sourceClamping&ClampLeft != 0 {
+		left = 0
+	}
+	if r.sourceClamping&ClampTop != 0 {
+		top = 0
+	}
+	if r.sourceClamping&ClampRight != 0 {
+		right = 0
+	}
+	if r.sourceClamping&ClampBottom != 0 {
+		bottom = 0
+	}
+	return left, top, right, bottom
+}
+
+// setClampingUniform sets the "Clamping" uniform used by the effect shaders.
+func (r *Renderer) setClampingUniform() {
+	r.opts.Uniforms["Clamping"] = int(r.sourceClamping)
+}
+
+// setSoftEdgeUniform sets the "SoftEdge" uniform used by the shape shaders, in local
+// units. smoothstep is undefined for equal edges, so zero is passed as a tiny value.
+func (r *Renderer) setSoftEdgeUniform() {
+	if r.pixelPerfect {
+		r.opts.Uniforms["SoftEdge"] = float32(0.001)
+	} else {
+		r.opts.Uniforms["SoftEdge"] = max(r.localSoftEdge(r.softEdge), 0.001)
+	}
+}
+
+// snapCenter moves the given point to the center of its pixel in pixel-perfect mode.
+func (r *Renderer) snapCenter(cx, cy float32) (float32, float32) {
+	if !r.pixelPerfect {
+		return cx, cy
+	}
+	return snapToPixelCenter(cx), snapToPixelCenter(cy)
+}
+
+// snapRadius rounds the given radius in pixel-perfect mode. See [Renderer.SetPixelPerfect]().
+func (r *Renderer) snapRadius(radius float32) float32 {
+	if !r.pixelPerfect {
+		return radius
+	}
+	return roundF32(radius) + 0.5
+}
+
+// snapArea rounds the given area values in pixel-perfect mode.
+func (r *Renderer) snapArea(ox, oy, w, h, rounding float32) (float32, float32, float32, float32, float32) {
+	if !r.pixelPerfect {
+		return ox, oy, w, h, rounding
+	}
+	return roundF32(ox), roundF32(oy), roundF32(w), roundF32(h), roundF32(rounding)
+}
+
+// snapLine adjusts the line ends and thickness in pixel-perfect mode.
+func (r *Renderer) snapLine(ox, oy, fx, fy, thickness float64) (float64, float64, float64, float64, float64) {
+	if !r.pixelPerfect {
+		return ox, oy, fx, fy, thickness
+	}
+	thickness = max(math.Round(thickness), 1)
+	if math.Mod(thickness, 2) == 1 {
+		ox, oy = snapToPixelCenter(ox), snapToPixelCenter(oy)
+		fx, fy = snapToPixelCenter(fx), snapToPixelCenter(fy)
+	} else {
+		ox, oy, fx, fy = math.Round(ox), math.Round(oy), math.Round(fx), math.Round(fy)
+	}
+	return ox, oy, fx, fy, thickness
+}
+
+// snapTriangle rounds the triangle vertices, thickness and rounding in pixel-perfect mode.
+func (r *Renderer) snapTriangle(ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding float64) (float64, float64, float64, float64, float64, float64, float64, float64) {
+	if !r.pixelPerfect {
+		return ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding
+	}
+	ox1, oy1, ox2, oy2 = math.Round(ox1), math.Round(oy1), math.Round(ox2), math.Round(oy2)
+	ox3, oy3 = math.Round(ox3), math.Round(oy3)
+	return ox1, oy1, ox2, oy2, ox3, oy3, math.Round(thickness), math.Round(rounding)
+}
+
+// splitRounding converts the given rounding value into inner and outer rounding
+// amounts. Following the package conventions, positive values expand the shape
+// (outer rounding) and negative values round the shape within its original bounds
+// (inner rounding). In legacy mode, the value is always returned as inner rounding.
+func (r *Renderer) splitRounding(rounding float32) (inner, outer float32) {
+	if r.legacyRounding {
+		return rounding, 0
+	}
+	return splitRounding(rounding)
+}
+
+// splitRounding is the renderer independent version of [Renderer.splitRounding](),
+// always following the current rounding conventions.
+func splitRounding(rounding float32) (inner, outer float32) {
+	if rounding < 0 {
+		return -rounding, 0
+	}
+	return 0, rounding
+}
+
 func (r *Renderer) Options() *ebiten.DrawTrianglesShaderOptions {
 	return &r.opts
 }
@@ -92,7 +288,7 @@ func (r *Renderer) DrawShaderAt(target, source *ebiten.Image, ox, oy, horzMargin
 	r.setSrcRectCoords(srcOX-horzMargin, srcOY-vertMargin, srcOX+srcWidthF32+horzMargin, srcOY+srcHeightF32+vertMargin)
 
 	r.opts.Images[0] = source
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shader, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shader)
 	r.opts.Images[0] = nil
 }
 
@@ -101,12 +297,15 @@ func (r *Renderer) DrawRectShader(target *ebiten.Image, ox, oy, w, h, horzMargin
 	dstOX, dstOY = dstOX+ox, dstOY+oy
 	r.setDstRectCoords(dstOX-horzMargin, dstOY-vertMargin, dstOX+w+horzMargin, dstOY+h+vertMargin)
 	r.setSrcRectCoords(-horzMargin, -vertMargin, w+horzMargin, h+vertMargin)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shader, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shader)
 }
 
+// DrawShader draws the shader over the whole target, with source coordinates
+// relative to the target's origin. See also [Renderer.DrawRectShader]().
 func (r *Renderer) DrawShader(target *ebiten.Image, horzMargin, vertMargin float32, shader *ebiten.Shader) {
-	bounds := target.Bounds()
-	r.DrawRectShader(target, 0, 0, float32(bounds.Dx()), float32(bounds.Dy()), horzMargin, vertMargin, shader)
+	minX, minY, maxX, maxY := r.localTargetBounds(target)
+	r.setLocalRectCoords(target, minX-horzMargin, minY-vertMargin, maxX+horzMargin, maxY+vertMargin)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shader)
 }
 
 // Scale draws the source into the given target with two differences from Ebitengine's scaling:
@@ -132,7 +331,7 @@ func (r *Renderer) Scale(target, source *ebiten.Image, ox, oy, scale float32, sc
 	} else {
 		r.setFlatCustomVAs(1.0, 1.0, 0, 0)
 	}
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderBilinear, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderBilinear)
 	r.opts.Images[0] = nil
 }
 
@@ -143,7 +342,11 @@ func (r *Renderer) Scale(target, source *ebiten.Image, ox, oy, scale float32, sc
 // The offscreens returned by this function should only be used for local operations, and
 // the offscreen must not be stored. Any renderer function documented to use an internal
 // offscreen can panic or fail in any other way if an offscreen returned by this function
-// if passed as an input parameter.
+// if passed as an input parameter. In debug builds (shapesdebug build tag), this is
+// detected and reported with a panic, at least when the offscreen is passed directly.
+//
+// Prefer [OffscreenPool.Lease]() unless you know which internal offscreens are used by
+// the functions you are calling.
 func (r *Renderer) UnsafeTemp(offscreenIndex int, w, h int) *ebiten.Image {
 	return r.getTemp(offscreenIndex, w, h, false)
 }
@@ -162,7 +365,7 @@ func (r *Renderer) UnsafeTempCopy(offscreenIndex int, source *ebiten.Image, clea
 	temp := r.getTemp(offscreenIndex, bounds.Dx(), bounds.Dy(), clear)
 	var opts ebiten.DrawImageOptions
 	opts.Blend = ebiten.BlendCopy
-	temp.DrawImage(source, &opts)
+	r.drawImage(temp, source, &opts)
 	return temp
 }
 
@@ -188,6 +391,35 @@ func (r *Renderer) setSrcRectCoords(minX, minY, maxX, maxY float32) {
 	r.vertices[3].SrcY = maxY
 }
 
+// setLocalRectCoords sets the destination coordinates for the given rect, relative to
+// the target's origin, and the same relative coordinates as source coordinates. Shape
+// shaders use the source coordinates as their local position, so they keep working when
+// the destination vertices are transformed by [Renderer.SetGeoM]().
+func (r *Renderer) setLocalRectCoords(target *ebiten.Image, minX, minY, maxX, maxY float32) {
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	r.setDstRectCoords(dstOX+minX, dstOY+minY, dstOX+maxX, dstOY+maxY)
+	r.setSrcRectCoords(minX, minY, maxX, maxY)
+}
+
+// setMaskRectCoords sets the destination and source coordinates for drawing the mask
+// at (ox, oy) on the target, extended by the given margin on each side, except on the
+// sides clamped with [Renderer.SetSourceClamping]().
+func (r *Renderer) setMaskRectCoords(target, mask *ebiten.Image, ox, oy, margin float32) {
+	left, top, right, bottom := r.clampMargins(margin)
+	r.setMaskRectCoordsWithMargins(target, mask, ox, oy, left, top, right, bottom)
+}
+
+// setMaskRectCoordsWithMargins is like [Renderer.setMaskRectCoords](), but with explicit
+// margins for each side.
+func (r *Renderer) setMaskRectCoordsWithMargins(target, mask *ebiten.Image, ox, oy, left, top, right, bottom float32) {
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	dstOX, dstOY = dstOX+ox, dstOY+oy
+	srcMinX, srcMinY, srcMaxX, srcMaxY := rectPointsF32(mask.Bounds())
+	width, height := srcMaxX-srcMinX, srcMaxY-srcMinY
+	r.setDstRectCoords(dstOX-left, dstOY-top, dstOX+width+right, dstOY+height+bottom)
+	r.setSrcRectCoords(srcMinX-left, srcMinY-top, srcMaxX+right, srcMaxY+bottom)
+}
+
 func (r *Renderer) setFlatCustomVAs(cva0, cva1, cva2, cva3 float32) {
 	for i := range len(r.vertices) {
 		r.vertices[i].Custom0 = cva0
@@ -228,12 +460,60 @@ func (r *Renderer) SetCustomVAs(vas ...float32) {
 	}
 }
 
-func (r *Renderer) getTemp(offscreenIndex int, w, h int, clear bool) *ebiten.Image {
-	if offscreenIndex >= len(r.temps) {
-		growth := offscreenIndex + 1 - len(r.temps)
-		r.temps = slices.Grow(r.temps, growth)
-		r.temps = r.temps[:offscreenIndex+1]
-		r.temps[offscreenIndex] = newOffscreen(0, 0, 64)
+// getAuxVertices returns a scratch slice of n vertices. The vertices are not
+// reset, so all relevant fields must be set by the caller.
+func (r *Renderer) getAuxVertices(n int) []ebiten.Vertex {
+	r.auxVertices = slices.Grow(r.auxVertices[:0], n)[:n]
+	return r.auxVertices
+}
+
+// getAuxIndices returns a scratch slice of n indices, meant to be
+// used together with [Renderer.getAuxVertices]().
+func (r *Renderer) getAuxIndices(n int) []uint16 {
+	r.auxIndices = slices.Grow(r.auxIndices[:0], n)[:n]
+	return r.auxIndices
+}
+
+// getTemp returns the internal offscreen with the given index, resized to w x h.
+// The images that the caller will read or write while using the offscreen must be
+// passed as inputs, so aliasing can be detected in debug builds.
+func (r *Renderer) getTemp(offscreenIndex int, w, h int, clear bool, inputs ...*ebiten.Image) *ebiten.Image {
+	r.pool.checkTempAliasing(offscreenIndex, inputs...)
+	temp, prevParent := r.pool.temp(offscreenIndex, w, h, clear)
+	r.stats.trackOffscreen(prevParent, r.pool.temps[offscreenIndex].parent)
+	return temp
+}
+
+// beginInternalPass must be called before drawing to internal offscreens, which
+// are always drawn without the renderer's GeoM and clip rect, and followed by a
+// matching [Renderer.endInternalPass]() call.
+func (r *Renderer) beginInternalPass() {
+	r.internalPasses += 1
+}
+
+func (r *Renderer) endInternalPass() {
+	r.internalPasses -= 1
+}
+
+// SetOffscreenPool sets the pool used for the renderer's internal offscreens. By
+// default, each renderer has its own pool, but sharing a pool between renderers
+// reduces memory usage. See [OffscreenPool] for details.
+func (r *Renderer) SetOffscreenPool(pool *OffscreenPool) {
+	if pool == nil {
+		panic("nil pool")
 	}
-	return r.temps[offscreenIndex].WithSize(w, h, clear)
+	r.pool = pool
+}
+
+// ReleaseTemps releases the renderer's internal offscreens. See [OffscreenPool.ReleaseTemps]().
+// If the pool is shared with other renderers, their offscreens are also released.
+func (r *Renderer) ReleaseTemps() {
+	r.pool.ReleaseTemps()
+}
+
+// OffscreenPool returns the pool used for the renderer's internal offscreens.
+// Offscreens can be leased from it with [OffscreenPool.Lease](), which is the
+// safe alternative to [Renderer.UnsafeTemp]().
+func (r *Renderer) OffscreenPool() *OffscreenPool {
+	return r.pool
 }
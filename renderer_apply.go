@@ -16,24 +16,15 @@ func (r *Renderer) ApplyExpansion(target *ebiten.Image, mask *ebiten.Image, ox,
 		panic("thickness can't exceed 32")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-	ht32 := thickness / 2.0
-	dstBounds := target.Bounds()
-	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
-	minX, minY := dstMinX+ox-ht32, dstMinY+oy-ht32
-	maxX, maxY := dstMinX+ox+srcWidth+ht32, dstMinY+oy+srcHeight+ht32
-	r.setDstRectCoords(minX-1, minY-1, maxX+1, maxY+1)
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX-ht32-1, srcMinY-ht32-1, srcMaxX+ht32+1, srcMaxY+ht32+1)
+	r.setMaskRectCoords(target, mask, ox, oy, thickness/2.0+1)
 	r.setFlatCustomVA0(thickness)
 
 	// draw shader
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderExpansionLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderExpansion, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderExpansion)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 }
 
@@ -51,29 +42,34 @@ func (r *Renderer) ApplyExpansionRect(target *ebiten.Image, mask *ebiten.Image,
 
 	// first pass (vert)
 	thickCeil := float32(math.Ceil(float64(thickness)))
+	left, top, right, bottom := r.clampMargins(thickCeil)
 	sx, sy, sw, sh := rectOriginSize(mask.Bounds())
-	temp := r.getTemp(0, sw, sh+int(thickCeil)*2.0, false)
+	temp := r.getTemp(0, sw, sh+int(top+bottom), false, target, mask)
 	sx32, sy32, sw32, sh32 := float32(sx), float32(sy), float32(sw), float32(sh)
 	memoBlend := r.opts.Blend
 	r.opts.Blend = ebiten.BlendCopy
-	r.setSrcRectCoords(sx32, sy32-thickCeil, sx32+sw32, sy32+sh32+thickCeil)
-	r.setDstRectCoords(0, 0, sw32, sh32+thickCeil*2)
+	r.setSrcRectCoords(sx32, sy32-top, sx32+sw32, sy32+sh32+bottom)
+	r.setDstRectCoords(0, 0, sw32, sh32+top+bottom)
 	r.setFlatCustomVA0(thickness)
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderExpansionVertLoaded()
-	temp.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderExpansionVert, &r.opts)
+	r.beginInternalPass()
+	r.drawTrianglesShader(temp, r.vertices[:], r.indices[:], shaderExpansionVert)
+	r.endInternalPass()
 	r.opts.Images[0] = nil
 
 	// second pass (horz)
 	r.opts.Blend = memoBlend
-	r.setSrcRectCoords(-thickCeil, 0, sw32+thickCeil, sh32+thickCeil*2.0)
+	r.setSrcRectCoords(-left, 0, sw32+right, sh32+top+bottom)
 	dx, dy := rectOriginF32(target.Bounds())
 	ox += dx
 	oy += dy
-	r.setDstRectCoords(ox-thickCeil, oy-thickCeil, ox+sw32+thickCeil, oy+sh32+thickCeil)
+	r.setDstRectCoords(ox-left, oy-top, ox+sw32+right, oy+sh32+bottom)
 	r.opts.Images[0] = temp
 	ensureShaderExpansionHorzLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderExpansionHorz, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderExpansionHorz)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 }
 
@@ -86,23 +82,15 @@ func (r *Renderer) ApplyErosion(target *ebiten.Image, mask *ebiten.Image, ox, oy
 		panic("thickness can't exceed 32")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-	dstBounds := target.Bounds()
-	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
-	minX, minY := dstMinX+ox, dstMinY+oy
-	maxX, maxY := dstMinX+ox+srcWidth, dstMinY+oy+srcHeight
-	r.setDstRectCoords(minX-1, minY-1, maxX+1.0, maxY+1.0)
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX-1, srcMinY-1, srcMaxX+1.0, srcMaxY+1.0)
+	r.setMaskRectCoords(target, mask, ox, oy, 1)
 	r.setFlatCustomVA0(thickness)
 
 	// draw shader
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderErosionLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderErosion, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderErosion)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 }
 
@@ -115,24 +103,15 @@ func (r *Renderer) ApplyOutline(target *ebiten.Image, mask *ebiten.Image, ox, oy
 		panic("thickness can't exceed 32")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-	ht32 := thickness / 2.0
-	dstBounds := target.Bounds()
-	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
-	minX, minY := dstMinX+ox-ht32, dstMinY+oy-ht32
-	maxX, maxY := dstMinX+ox+srcWidth+ht32, dstMinY+oy+srcHeight+ht32
-	r.setDstRectCoords(minX-1, minY-1, maxX+1, maxY+1)
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX-ht32-1, srcMinY-ht32-1, srcMaxX+ht32+1.0, srcMaxY+ht32+1.0)
+	r.setMaskRectCoords(target, mask, ox, oy, thickness/2.0+1)
 	r.setFlatCustomVA0(thickness)
 
 	// draw shader
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderOutlineLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderOutline, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderOutline)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 }
 
@@ -149,24 +128,15 @@ func (r *Renderer) ApplyBlur(target *ebiten.Image, mask *ebiten.Image, ox, oy, r
 		panic("radius can't be negative")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-	hr32 := radius / 2.0
-	dstBounds := target.Bounds()
-	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
-	minX, minY := dstMinX+ox-hr32, dstMinY+oy-hr32
-	maxX, maxY := dstMinX+ox+srcWidth+hr32, dstMinY+oy+srcHeight+hr32
-	r.setDstRectCoords(minX-1, minY-1, maxX+1, maxY+1)
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX-hr32-1, srcMinY-hr32-1, srcMaxX+hr32+1.0, srcMaxY+hr32+1.0)
+	r.setMaskRectCoords(target, mask, ox, oy, radius/2.0+1)
 	r.setFlatCustomVAs01(radius, colorMix)
 
 	// draw shader
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderBlurLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderBlur, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderBlur)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 }
 
@@ -184,15 +154,16 @@ func (r *Renderer) ApplyBlur2(target *ebiten.Image, mask *ebiten.Image, ox, oy,
 	}
 
 	srcBounds := mask.Bounds()
-	w32, h32 := float32(srcBounds.Dx()), float32(srcBounds.Dy())+radius
-	w, h := int(w32), int(math.Ceil(float64(h32)))
-	tmp := r.getTemp(0, w, h, false)
+	_, top, _, bottom := r.clampMargins(ceilF32(radius / 2.0))
+	w, h := srcBounds.Dx(), srcBounds.Dy()+int(top+bottom)
+	tmp := r.getTemp(0, w, h, false, target, mask)
 	preBlend := r.opts.Blend
 	r.opts.Blend = ebiten.BlendCopy
-	hrCeil := ceilF32(radius / 2.0)
-	r.ApplyVertBlur(tmp, mask, 0, hrCeil, radius, 1.0)
+	r.beginInternalPass()
+	r.ApplyVertBlur(tmp, mask, 0, top, radius, 1.0)
+	r.endInternalPass()
 	r.opts.Blend = preBlend
-	r.ApplyHorzBlur(target, tmp, ox, oy-hrCeil, radius, colorMix)
+	r.ApplyHorzBlur(target, tmp, ox, oy-top, radius, colorMix)
 }
 
 func (r *Renderer) ApplyVertBlur(target *ebiten.Image, mask *ebiten.Image, ox, oy, radius, colorMix float32) {
@@ -203,24 +174,16 @@ func (r *Renderer) ApplyVertBlur(target *ebiten.Image, mask *ebiten.Image, ox, o
 		panic("radius can't be negative")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-	dstBounds := target.Bounds()
-	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
-	hrCeil := ceilF32(radius / 2.0)
-	minX, minY := dstMinX+ox, dstMinY+oy-hrCeil
-	maxX, maxY := dstMinX+ox+srcWidth, dstMinY+oy+srcHeight+hrCeil
-	r.setDstRectCoords(minX, minY, maxX, maxY)
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX, srcMinY-hrCeil, srcMaxX, srcMaxY+hrCeil)
+	_, top, _, bottom := r.clampMargins(ceilF32(radius / 2.0))
+	r.setMaskRectCoordsWithMargins(target, mask, ox, oy, 0, top, 0, bottom)
 	r.setFlatCustomVAs01(radius, colorMix)
 
 	// draw shader
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderVertBlurLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderVertBlur, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderVertBlur)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 }
 
@@ -232,24 +195,16 @@ func (r *Renderer) ApplyHorzBlur(target *ebiten.Image, mask *ebiten.Image, ox, o
 		panic("radius can't be negative")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-	hrCeil := ceilF32(radius / 2.0)
-	dstBounds := target.Bounds()
-	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
-	minX, minY := dstMinX+ox-hrCeil, dstMinY+oy
-	maxX, maxY := dstMinX+ox+srcWidth+hrCeil, dstMinY+oy+srcHeight
-	r.setDstRectCoords(minX, minY, maxX, maxY)
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX-hrCeil, srcMinY, srcMaxX+hrCeil, srcMaxY)
+	left, _, right, _ := r.clampMargins(ceilF32(radius / 2.0))
+	r.setMaskRectCoordsWithMargins(target, mask, ox, oy, left, 0, right, 0)
 	r.setFlatCustomVAs01(radius, colorMix)
 
 	// draw shader
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderHorzBlurLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderHorzBlur, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderHorzBlur)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 }
 
@@ -266,9 +221,11 @@ const (
 	ClampTopRight    Clamping = ClampTop | ClampRight
 	ClampBottomLeft  Clamping = ClampBottom | ClampLeft
 	ClampBottomRight Clamping = ClampBottom | ClampRight
+	ClampAll         Clamping = ClampTop | ClampBottom | ClampLeft | ClampRight
 )
 
 func (r *Renderer) ApplyHardShadow(target *ebiten.Image, mask *ebiten.Image, ox, oy, xOffset, yOffset float32, clamping Clamping) {
+	clamping |= r.sourceClamping
 	dstBounds, srcBounds := target.Bounds(), mask.Bounds()
 	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
 	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
@@ -286,7 +243,7 @@ func (r *Renderer) ApplyHardShadow(target *ebiten.Image, mask *ebiten.Image, ox,
 	// draw shader
 	r.opts.Images[0] = mask
 	ensureShaderHardShadowLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderHardShadow, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderHardShadow)
 	r.opts.Images[0] = nil
 }
 
@@ -298,6 +255,7 @@ func (r *Renderer) ApplyShadow(target *ebiten.Image, mask *ebiten.Image, ox, oy,
 		panic("radius can't be negative")
 	}
 
+	clamping |= r.sourceClamping
 	dstBounds, srcBounds := target.Bounds(), mask.Bounds()
 	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
 	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
@@ -330,7 +288,7 @@ func (r *Renderer) ApplyShadow(target *ebiten.Image, mask *ebiten.Image, ox, oy,
 	// draw shader
 	r.opts.Images[0] = mask
 	ensureShaderShadowLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderShadow, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderShadow)
 	r.opts.Images[0] = nil
 }
 
@@ -338,6 +296,7 @@ func (r *Renderer) ApplyZoomShadow(target *ebiten.Image, mask *ebiten.Image, ox,
 	if zoom < 1.0 || zoom > 16.0 {
 		panic("zoom must be in [1, 16] range")
 	}
+	clamping |= r.sourceClamping
 
 	dstBounds, srcBounds := target.Bounds(), mask.Bounds()
 	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
@@ -376,7 +335,7 @@ func (r *Renderer) ApplyZoomShadow(target *ebiten.Image, mask *ebiten.Image, ox,
 	// draw shader
 	r.opts.Images[0] = mask
 	ensureShaderZoomShadowLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderZoomShadow, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderZoomShadow)
 	r.opts.Images[0] = nil
 }
 
@@ -409,30 +368,28 @@ func (r *Renderer) ApplyGlow(target *ebiten.Image, mask *ebiten.Image, ox, oy, h
 	}
 
 	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-	w32, h32 := float32(srcWidth), float32(srcHeight)+vertRadius
+	_, top, _, bottom := r.clampMargins(vertRadius/2.0 + 1.0)
+	w32, h32 := float32(srcBounds.Dx()), float32(srcBounds.Dy())+top+bottom
 	w, h := int(w32), int(math.Ceil(float64(h32)))
-	tmp := r.getTemp(0, w, h, false)
-
-	hr32 := vertRadius / 2.0
-	r.setDstRectCoords(0, 0, w32, h32+2)
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX, srcMinY-hr32-1, srcMaxX, srcMaxY+hr32+1.0)
+	tmp := r.getTemp(0, w, h, false, target, mask)
+	r.setMaskRectCoordsWithMargins(tmp, mask, 0, top, 0, top, 0, bottom)
 	r.setFlatCustomVAs(vertRadius, threshStart, threshEnd, 1.0)
 
 	// first pass (threshold + vertical blur)
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	preBlend := r.opts.Blend
 	r.opts.Blend = ebiten.BlendCopy
 	ensureShaderGlowFirstPassLoaded()
-	tmp.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderGlowFirstPass, &r.opts)
+	r.beginInternalPass()
+	r.drawTrianglesShader(tmp, r.vertices[:], r.indices[:], shaderGlowFirstPass)
+	clear(r.opts.Uniforms)
+	r.endInternalPass()
 	r.opts.Images[0] = nil
 
 	// second pass
 	r.opts.Blend = ebiten.BlendLighter
-	r.ApplyHorzBlur(target, tmp, ox, oy-hr32-1.0, horzRadius, colorMix)
+	r.ApplyHorzBlur(target, tmp, ox, oy-top, horzRadius, colorMix)
 	r.opts.Blend = preBlend
 }
 
@@ -448,22 +405,17 @@ func (r *Renderer) ApplyHorzGlow(target *ebiten.Image, mask *ebiten.Image, ox, o
 		panic("radius can't exceed 32")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-
-	hr32 := horzRadius / 2.0
-	r.setDstRectCoords(ox-hr32-1.0, oy, ox+float32(srcWidth)+hr32+1.0, oy+float32(srcHeight))
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX-hr32-1, srcMinY, srcMaxX+hr32+1, srcMaxY)
+	left, _, right, _ := r.clampMargins(horzRadius/2.0 + 1.0)
+	r.setMaskRectCoordsWithMargins(target, mask, ox, oy, left, 0, right, 0)
 	r.setFlatCustomVAs(horzRadius, threshStart, threshEnd, colorMix)
 
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderHorzGlowLoaded()
 	preBlend := r.opts.Blend
 	r.opts.Blend = ebiten.BlendLighter
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderHorzGlow, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderHorzGlow)
+	clear(r.opts.Uniforms)
 	r.opts.Blend = preBlend
 	r.opts.Images[0] = nil
 }
@@ -481,23 +433,18 @@ func (r *Renderer) ApplyDarkHorzGlow(target *ebiten.Image, mask *ebiten.Image, o
 		panic("radius can't exceed 32")
 	}
 
-	srcBounds := mask.Bounds()
-	srcWidth, srcHeight := float32(srcBounds.Dx()), float32(srcBounds.Dy())
-
-	hr32 := horzRadius / 2.0
-	r.setDstRectCoords(ox-hr32-1.0, oy, ox+float32(srcWidth)+hr32+1.0, oy+float32(srcHeight))
-
-	srcMinX, srcMinY := float32(srcBounds.Min.X), float32(srcBounds.Min.Y)
-	srcMaxX, srcMaxY := float32(srcBounds.Max.X), float32(srcBounds.Max.Y)
-	r.setSrcRectCoords(srcMinX-hr32-1, srcMinY, srcMaxX+hr32+1, srcMaxY)
+	left, _, right, _ := r.clampMargins(horzRadius/2.0 + 1.0)
+	r.setMaskRectCoordsWithMargins(target, mask, ox, oy, left, 0, right, 0)
 	r.setFlatCustomVAs(horzRadius, threshStart, threshEnd, colorMix)
 
 	r.opts.Images[0] = mask
+	r.setClampingUniform()
 	ensureShaderDarkHorzGlowLoaded()
 	preBlend := r.opts.Blend
 	r.opts.Blend = BlendMultiply
 	//r.opts.Blend = BlendSubtract // also possible with a shader flag, but multiply feels more natural
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderDarkHorzGlow, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderDarkHorzGlow)
+	clear(r.opts.Uniforms)
 	r.opts.Blend = preBlend
 	r.opts.Images[0] = nil
 }
@@ -549,7 +496,7 @@ func (r *Renderer) ApplyBlurD4(target *ebiten.Image, mask *ebiten.Image, ox, oy
 	r.applyKernelD4(target, mask, ox, oy, horzKernel, vertKernel, func(downHorzTarget *ebiten.Image) {
 		r.setFlatCustomVA0(colorMix)
 		ensureShaderHorzBlurKernLoaded()
-		downHorzTarget.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderHorzBlurKern, &r.opts)
+		r.drawTrianglesShader(downHorzTarget, r.vertices[:], r.indices[:], shaderHorzBlurKern)
 	}, false)
 }
 
@@ -566,7 +513,7 @@ func (r *Renderer) ApplyGlowD4(target *ebiten.Image, mask *ebiten.Image, ox, oy
 	r.applyKernelD4(target, mask, ox, oy, horzKernel, vertKernel, func(downHorzTarget *ebiten.Image) {
 		r.setFlatCustomVAs(threshStart, threshEnd, colorMix, 0)
 		ensureShaderHorzGlowKernLoaded()
-		downHorzTarget.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderHorzGlowKern, &r.opts)
+		r.drawTrianglesShader(downHorzTarget, r.vertices[:], r.indices[:], shaderHorzGlowKern)
 	}, true)
 }
 
@@ -588,7 +535,7 @@ func (r *Renderer) ApplyColorGlowD4(target *ebiten.Image, mask *ebiten.Image, ox
 		r.opts.Uniforms["RGB"] = rgb
 		r.setFlatCustomVAs(threshStart, threshEnd, colorMix, 0)
 		ensureShaderHorzColorGlowLoaded()
-		downHorzTarget.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderHorzColorGlow, &r.opts)
+		r.drawTrianglesShader(downHorzTarget, r.vertices[:], r.indices[:], shaderHorzColorGlow)
 		clear(r.opts.Uniforms)
 	}, true)
 }
@@ -616,10 +563,11 @@ func (r *Renderer) applyKernelD4(target *ebiten.Image, mask *ebiten.Image, ox, o
 	dkernImgWidth, dkernImgHeight := math.Ceil(dkernW64)+2, math.Ceil(dkernH64)+2
 
 	// get offscreens and smart clears
-	dkern := r.getTemp(0, int(dkernImgWidth), int(dkernImgHeight), false) // get first as the biggest offscreen
-	down := r.getTemp(0, int(downImgWidth), int(downImgHeight), false)    // shared with dkern
-	dkernHorz := r.getTemp(1, int(dkernImgWidth), int(downImgHeight), false)
+	dkern := r.getTemp(0, int(dkernImgWidth), int(dkernImgHeight), false, target, mask) // get first as the biggest offscreen
+	down := r.getTemp(0, int(downImgWidth), int(downImgHeight), false)                  // shared with dkern
+	dkernHorz := r.getTemp(1, int(dkernImgWidth), int(downImgHeight), false, target, mask)
 	preBlend := r.opts.Blend
+	r.beginInternalPass()
 	r.opts.Blend = ebiten.BlendClear
 	r.StrokeIntRect(down, down.Bounds(), 0, 2)
 	r.DrawIntRect(dkern, clockwiseRightBorder(dkern.Bounds(), 1)) // *
@@ -651,9 +599,10 @@ func (r *Renderer) applyKernelD4(target *ebiten.Image, mask *ebiten.Image, ox, o
 	r.setSrcRectCoords(0, float32(-halfVertMargin), float32(dkernW64)+2, float32(downH64+halfVertMargin)+2)
 	r.opts.Images[0] = dkernHorz
 	ensureShaderVertBlurKernLoaded()
-	dkern.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderVertBlurKern, &r.opts)
+	r.drawTrianglesShader(dkern, r.vertices[:], r.indices[:], shaderVertBlurKern)
 	r.opts.Images[0] = nil
 	clear(r.opts.Uniforms)
+	r.endInternalPass()
 
 	// upscale
 	if lighterBlend {
@@ -0,0 +1,249 @@
+package shapes
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// maxBatchQuads is the maximum number of quads that can be drawn in
+// a single DrawTrianglesShader call while using uint16 indices.
+const maxBatchQuads = 16383
+
+// Shape kinds for [ShapeBatch]. The values are passed to the uber shader
+// and must match the constants in shaders/batch_uber.kage.
+const (
+	batchKindCircle = iota
+	batchKindRect
+	batchKindRing
+	batchKindLine
+	batchKindCount
+)
+
+// ShapeBatch accumulates shapes and draws them with a single DrawTrianglesShader
+// call per shape kind, instead of one call per shape. All the shape parameters are
+// passed as custom vertex attributes, so shapes with different sizes, roundings or
+// colors can still be batched together. This is most useful for particles or
+// other scenes with hundreds or thousands of small shapes.
+//
+// Shapes use the renderer's vertex colors at the time they are added, and the
+// renderer's blend and soft edge at the time the batch is flushed. Shapes of the
+// same kind are drawn in the order they were added, but each kind is drawn in its
+// own pass, so mixed kinds might not preserve the overall order. If you need to mix
+// shape kinds, see [ShapeBatch.SetUberShader]().
+//
+// Batches can be reused across frames to avoid allocations:
+//
+//	batch := renderer.NewShapeBatch()
+//	for _, p := range particles {
+//		renderer.SetColor(p.Color)
+//		batch.AddCircle(p.X, p.Y, p.Radius)
+//	}
+//	batch.Flush(screen)
+type ShapeBatch struct {
+	renderer *Renderer
+	passes   [batchKindCount][]ebiten.Vertex
+	mixed    []ebiten.Vertex // used instead of passes in uber shader mode
+	indices  []uint16
+	uber     bool
+}
+
+// NewShapeBatch creates a new empty batch for the renderer.
+func (r *Renderer) NewShapeBatch() *ShapeBatch {
+	return &ShapeBatch{renderer: r}
+}
+
+// SetUberShader enables or disables the uber shader mode. In this mode, all shapes
+// are drawn with a single shader that receives the shape kind as a vertex attribute,
+// so mixed shape kinds can be drawn in a single call and in the order they were added.
+// This is useful for UIs and other scenes where different shapes are interleaved, at
+// the cost of slightly more expensive shading per pixel.
+//
+// The function panics if the batch is not empty.
+func (b *ShapeBatch) SetUberShader(enabled bool) {
+	if b.Len() > 0 {
+		panic("can't change the shader mode of a non-empty batch")
+	}
+	b.uber = enabled
+}
+
+// Len returns the number of shapes currently in the batch.
+func (b *ShapeBatch) Len() int {
+	count := len(b.mixed)
+	for _, vertices := range b.passes {
+		count += len(vertices)
+	}
+	return count / 4
+}
+
+// Reset discards all the shapes in the batch without drawing them.
+func (b *ShapeBatch) Reset() {
+	for i := range b.passes {
+		b.passes[i] = b.passes[i][:0]
+	}
+	b.mixed = b.mixed[:0]
+}
+
+// AddCircle adds a circle to the batch. See [Renderer.DrawCircle]().
+func (b *ShapeBatch) AddCircle(cx, cy, radius float32) {
+	r := b.renderer
+	cx, cy = r.snapCenter(cx, cy)
+	radius = r.snapRadius(radius)
+	dst := rectCorners(cx-radius, cy-radius, cx+radius, cy+radius)
+	if b.uber {
+		src := rectCorners(-radius, -radius, radius, radius)
+		b.mixed = b.appendQuad(b.mixed, dst, src, batchKindCircle, radius, 0, 0)
+	} else {
+		b.passes[batchKindCircle] = b.appendQuad(b.passes[batchKindCircle], dst, dst, cx, cy, radius, 0)
+	}
+}
+
+// AddArea adds a rectangle to the batch. See [Renderer.DrawArea]().
+func (b *ShapeBatch) AddArea(ox, oy, w, h, rounding float32) {
+	r := b.renderer
+	if w < 0 {
+		w = -w
+		ox -= w
+	}
+	if h < 0 {
+		h = -h
+		oy -= h
+	}
+	ox, oy, w, h, rounding = r.snapArea(ox, oy, w, h, rounding)
+	inRounding, outRounding := r.splitRounding(rounding)
+	if outRounding > 0 {
+		ox, oy = ox-outRounding, oy-outRounding
+		w, h = w+outRounding*2, h+outRounding*2
+	}
+	rounding = min(inRounding, min(w, h)/2) + outRounding
+	dst := rectCorners(ox, oy, ox+w, oy+h)
+	src := rectCorners(0, 0, w, h)
+	if b.uber {
+		b.mixed = b.appendQuad(b.mixed, dst, src, batchKindRect, w, h, rounding)
+	} else {
+		b.passes[batchKindRect] = b.appendQuad(b.passes[batchKindRect], dst, src, w, h, rounding, 0)
+	}
+}
+
+// AddRect adds a rectangle to the batch. See [Renderer.DrawRect]().
+func (b *ShapeBatch) AddRect(rect image.Rectangle, rounding float32) {
+	b.AddArea(float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), rounding)
+}
+
+// AddRing adds a ring to the batch. See [Renderer.DrawRing]().
+func (b *ShapeBatch) AddRing(cx, cy, inRadius, outRadius float32) {
+	if inRadius >= outRadius {
+		return // skip empty draws
+	}
+	r := b.renderer
+	cx, cy = r.snapCenter(cx, cy)
+	inRadius, outRadius = r.snapRadius(inRadius), r.snapRadius(outRadius)
+	dst := rectCorners(cx-outRadius, cy-outRadius, cx+outRadius, cy+outRadius)
+	if b.uber {
+		src := rectCorners(-outRadius, -outRadius, outRadius, outRadius)
+		b.mixed = b.appendQuad(b.mixed, dst, src, batchKindRing, outRadius, inRadius, 0)
+	} else {
+		b.passes[batchKindRing] = b.appendQuad(b.passes[batchKindRing], dst, dst, cx, cy, outRadius, inRadius)
+	}
+}
+
+// AddLine adds a line to the batch. See [Renderer.DrawLine]().
+func (b *ShapeBatch) AddLine(ox, oy, fx, fy, thickness float64) {
+	ox, oy, fx, fy, thickness = b.renderer.snapLine(ox, oy, fx, fy, thickness)
+	length := math.Hypot(fx-ox, fy-oy)
+	dst := lineCorners(ox, oy, fx, fy, thickness)
+	src := lineSrcCorners(length, thickness)
+	if b.uber {
+		b.mixed = b.appendQuad(b.mixed, dst, src, batchKindLine, float32(length), float32(thickness), 0)
+	} else {
+		b.passes[batchKindLine] = b.appendQuad(b.passes[batchKindLine], dst, src, float32(length), float32(thickness), 0, 0)
+	}
+}
+
+// Flush draws all the shapes in the batch to the given target, relative to
+// the target's origin, and resets the batch.
+func (b *ShapeBatch) Flush(target *ebiten.Image) {
+	r := b.renderer
+	r.setSoftEdgeUniform()
+	if len(b.mixed) > 0 {
+		ensureShaderBatchUberLoaded()
+		b.draw(target, b.mixed, shaderBatchUber)
+	}
+	for kind, vertices := range b.passes {
+		if len(vertices) == 0 {
+			continue
+		}
+		b.draw(target, vertices, batchKindShader(kind))
+	}
+	clear(r.opts.Uniforms)
+	b.Reset()
+}
+
+// batchKindShader returns the shader used to draw the given
+// shape kind when the uber shader mode is disabled.
+func batchKindShader(kind int) *ebiten.Shader {
+	switch kind {
+	case batchKindCircle:
+		ensureShaderCircleLoaded()
+		return shaderCircle
+	case batchKindRect:
+		ensureShaderRectLoaded()
+		return shaderRect
+	case batchKindRing:
+		ensureShaderRingLoaded()
+		return shaderRing
+	case batchKindLine:
+		ensureShaderLineLoaded()
+		return shaderLine
+	default:
+		panic("invalid batch shape kind")
+	}
+}
+
+// draw moves the vertices to the target's origin and draws them
+// with the given shader, splitting them in chunks if necessary.
+func (b *ShapeBatch) draw(target *ebiten.Image, vertices []ebiten.Vertex, shader *ebiten.Shader) {
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	if dstOX != 0 || dstOY != 0 {
+		for i := range vertices {
+			vertices[i].DstX += dstOX
+			vertices[i].DstY += dstOY
+		}
+	}
+
+	r := b.renderer
+	for start := 0; start < len(vertices); start += maxBatchQuads * 4 {
+		end := min(start+maxBatchQuads*4, len(vertices))
+		indices := b.quadIndices((end - start) / 4)
+		r.drawTrianglesShader(target, vertices[start:end], indices, shader)
+	}
+}
+
+// quadIndices returns the indices for drawing the given number of quads.
+func (b *ShapeBatch) quadIndices(quads int) []uint16 {
+	for n := len(b.indices) / 6; n < quads; n++ {
+		i := uint16(n * 4)
+		b.indices = append(b.indices, i, i+1, i+2, i, i+2, i+3)
+	}
+	return b.indices[:quads*6]
+}
+
+// appendQuad appends the vertices of a quad with the renderer's current vertex colors.
+func (b *ShapeBatch) appendQuad(vertices []ebiten.Vertex, dst, src [4]PointF32, cva0, cva1, cva2, cva3 float32) []ebiten.Vertex {
+	for i := range 4 {
+		vertex := b.renderer.vertices[i]
+		vertex.DstX, vertex.DstY = dst[i].X, dst[i].Y
+		vertex.SrcX, vertex.SrcY = src[i].X, src[i].Y
+		vertex.Custom0, vertex.Custom1 = cva0, cva1
+		vertex.Custom2, vertex.Custom3 = cva2, cva3
+		vertices = append(vertices, vertex)
+	}
+	return vertices
+}
+
+// rectCorners returns the corners of the given rect in the
+// same order used by [Renderer.setDstRectCoords]().
+func rectCorners(minX, minY, maxX, maxY float32) [4]PointF32 {
+	return [4]PointF32{{X: minX, Y: minY}, {X: maxX, Y: minY}, {X: maxX, Y: maxY}, {X: minX, Y: maxY}}
+}
@@ -0,0 +1,231 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// go test -run ^TestShapeBatch$ . -count 1
+func TestShapeBatch(t *testing.T) {
+	const NumParticles = 4000
+	var batch *ShapeBatch
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		if batch == nil {
+			batch = ctx.Renderer.NewShapeBatch()
+		}
+
+		bounds := canvas.Bounds()
+		w, h := float64(bounds.Dx()), float64(bounds.Dy())
+		for i := range NumParticles {
+			fi := float64(i)
+			t := float64(ctx.Ticks)/120.0 + fi*0.37
+			x := w/2 + math.Cos(t*0.7+fi)*w*0.45*math.Sin(fi*0.013)
+			y := h/2 + math.Sin(t*0.9+fi*1.7)*h*0.45*math.Cos(fi*0.017)
+			size := float32(2 + i%5)
+			ctx.Renderer.SetColor(color.RGBA{uint8(i * 7), uint8(255 - i%256), 180, 255})
+			if i%3 == 0 {
+				batch.AddArea(float32(x)-size, float32(y)-size, size*2, size*2, -size/2)
+			} else {
+				batch.AddCircle(float32(x), float32(y), size)
+			}
+		}
+		batch.Flush(canvas)
+		ctx.Renderer.SetColor(color.White)
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestShapeBatchMatchesDraws$ . -count 1
+func TestShapeBatchMatchesDraws(t *testing.T) {
+	const w, h = 128, 96
+	const offX, offY = 17, 9
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		batch := r.NewShapeBatch()
+
+		parent := ebiten.NewImage(w+offX*2, h+offY*2)
+		batched := parent.SubImage(image.Rect(offX, offY, offX+w, offY+h)).(*ebiten.Image)
+		direct := ebiten.NewImage(w, h)
+		for i := range 12 {
+			fi := float32(i)
+			r.SetColor(color.RGBA{uint8(40 + i*16), 255, uint8(255 - i*16), 255})
+			cy, radius := float32(8+(i%2)*16), 3.2+float32(i%3)
+			r.DrawCircle(direct, 6+fi*10, cy, radius)
+			batch.AddCircle(6+fi*10, cy, radius)
+			oy, rh := float32(40+(i%2)*24), float32(14+i%4)
+			r.DrawArea(direct, 2+fi*10, oy, 8, rh, fi-6)
+			batch.AddArea(2+fi*10, oy, 8, rh, fi-6)
+		}
+		if batch.Len() != 24 {
+			fail("unexpected batch length before flushing")
+		}
+		batch.Flush(batched)
+		if batch.Len() != 0 {
+			fail("batch not reset after flushing")
+		}
+
+		// batches draw all circles before any rect, so circles
+		// and rects are kept apart to get the same results
+		directPix := make([]byte, w*h*4)
+		batchedPix := make([]byte, w*h*4)
+		direct.ReadPixels(directPix)
+		batched.ReadPixels(batchedPix)
+		if msg := comparePixels(directPix, batchedPix, w, 2); msg != "" {
+			fail("%s", msg)
+		} else if isTransparent(directPix) {
+			fail("reference output is fully transparent")
+		}
+	})
+}
+
+// go test -run ^TestShapeBatchUberShader$ . -count 1
+func TestShapeBatchUberShader(t *testing.T) {
+	var batch *ShapeBatch
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.RGBA{24, 24, 32, 255})
+		if batch == nil {
+			batch = ctx.Renderer.NewShapeBatch()
+			batch.SetUberShader(true)
+		}
+
+		// a grid of widgets mixing all shape kinds, drawn in a single call
+		bounds := canvas.Bounds()
+		lx, ly := ctx.LeftClickF32()
+		for y := 16; y+48 < bounds.Dy(); y += 56 {
+			for x := 16; x+96 < bounds.Dx(); x += 104 {
+				fx, fy := float32(x), float32(y)
+				ctx.Renderer.SetColor(color.RGBA{60, 60, 80, 255})
+				batch.AddArea(fx, fy, 96, 48, -8)
+				ctx.Renderer.SetColor(color.RGBA{200, 120, 40, 255})
+				batch.AddRing(fx+24, fy+24, 10, 16)
+				ctx.Renderer.SetColor(color.RGBA{240, 240, 240, 255})
+				batch.AddCircle(fx+24, fy+24, 6)
+				batch.AddLine(float64(fx+48), float64(fy+24), float64(fx+88), float64(fy+24+(ly-fy-24)/32), 3)
+				if lx >= fx && lx < fx+96 && ly >= fy && ly < fy+48 {
+					ctx.Renderer.SetColor(color.RGBA{80, 200, 255, 255})
+					batch.AddArea(fx, fy, 96, 48, 2)
+				}
+			}
+		}
+		batch.Flush(canvas)
+		ctx.Renderer.SetColor(color.White)
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestShapeBatchUberMatchesDraws$ . -count 1
+func TestShapeBatchUberMatchesDraws(t *testing.T) {
+	const w, h = 128, 96
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		uber := r.NewShapeBatch()
+		uber.SetUberShader(true)
+		lines := r.NewShapeBatch()
+
+		direct := ebiten.NewImage(w, h)
+		batched := ebiten.NewImage(w, h)
+		linesDirect := ebiten.NewImage(w, h)
+		linesBatched := ebiten.NewImage(w, h)
+		for i := range 8 {
+			fi := float32(i)
+			ox, oy := 8+fi*13, 10+fi*7
+
+			// overlapping shapes, so draw order matters
+			r.SetColor(color.RGBA{uint8(255 - i*24), 80, uint8(i * 24), 255})
+			r.DrawArea(direct, ox, oy, 30, 20, fi-4)
+			uber.AddArea(ox, oy, 30, 20, fi-4)
+			r.SetColor(color.RGBA{40, uint8(120 + i*16), 200, 255})
+			r.DrawRing(direct, ox+20, oy+18, 4, 9)
+			uber.AddRing(ox+20, oy+18, 4, 9)
+			r.SetColor(color.RGBA{255, 255, 255, 200})
+			r.DrawCircle(direct, ox+20, oy+18, 3.5)
+			uber.AddCircle(ox+20, oy+18, 3.5)
+			r.DrawLine(direct, float64(ox), float64(oy+30), float64(ox+24), float64(oy+4), 2.5)
+			uber.AddLine(float64(ox), float64(oy+30), float64(ox+24), float64(oy+4), 2.5)
+
+			// lines on their own pass without the uber shader mode
+			r.DrawLine(linesDirect, float64(ox), float64(oy), float64(ox+fi*3), float64(oy+40), 1+float64(i))
+			lines.AddLine(float64(ox), float64(oy), float64(ox+fi*3), float64(oy+40), 1+float64(i))
+		}
+		uber.Flush(batched)
+		lines.Flush(linesBatched)
+
+		directPix := make([]byte, w*h*4)
+		batchedPix := make([]byte, w*h*4)
+		for _, pair := range [][2]*ebiten.Image{{direct, batched}, {linesDirect, linesBatched}} {
+			pair[0].ReadPixels(directPix)
+			pair[1].ReadPixels(batchedPix)
+			if msg := comparePixels(directPix, batchedPix, w, 2); msg != "" {
+				fail("%s", msg)
+			} else if isTransparent(directPix) {
+				fail("reference output is fully transparent")
+			}
+		}
+	})
+}
+
+// go test -run ^TestDrawsKeepBatching$ . -count 1
+func TestDrawsKeepBatching(t *testing.T) {
+	const NumDraws = 24
+
+	draws := []struct {
+		name string
+		draw func(r *Renderer, target *ebiten.Image, i int)
+	}{
+		{"DrawArea", func(r *Renderer, target *ebiten.Image, i int) {
+			r.DrawArea(target, float32(i*5), float32(i*3), float32(10+i), float32(20-i/2), float32(i%7-3))
+		}},
+		{"DrawLine", func(r *Renderer, target *ebiten.Image, i int) {
+			r.DrawLine(target, float64(i*5), 10, float64(i*3), 90, 1+float64(i%5))
+		}},
+		{"DrawTriangle", func(r *Renderer, target *ebiten.Image, i int) {
+			r.DrawTriangle(target, float64(i*4), 10, float64(i*4+30), 20+float64(i), float64(i*4+10), 60, float64(i%7-3))
+		}},
+		{"StrokeTriangle", func(r *Renderer, target *ebiten.Image, i int) {
+			r.StrokeTriangle(target, float64(i*4), 10, float64(i*4+30), 20+float64(i), float64(i*4+10), 60, float64(i%5-2), float64(i%3))
+		}},
+		{"DrawHexagon", func(r *Renderer, target *ebiten.Image, i int) {
+			r.DrawHexagon(target, float32(10+i*5), 40, float32(8+i%6), float32(i%5-2), float32(i)*0.1)
+		}},
+		{"DrawQuad", func(r *Renderer, target *ebiten.Image, i int) {
+			fi := float32(i)
+			quad := [4]PointF32{{X: fi * 5, Y: 10}, {X: fi*5 + 20, Y: 12}, {X: fi*5 + 24, Y: 40 + fi}, {X: fi*5 - 2, Y: 36}}
+			r.DrawQuad(target, quad, fi/4-3)
+		}},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		target := ebiten.NewImage(160, 100)
+		for _, test := range draws {
+			r.ResetStats()
+			for i := range NumDraws {
+				r.SetColor(color.RGBA{uint8(i * 10), 128, 255, 255})
+				test.draw(r, target, i)
+			}
+			if batches := r.Stats().Batches; batches != 1 {
+				fail("%s: %d draws needed %d batches, expected 1", test.name, NumDraws, batches)
+			}
+		}
+
+		// interleaving shaders does break batching
+		r.ResetStats()
+		for i := range NumDraws {
+			draws[i%2].draw(r, target, i)
+		}
+		if batches := r.Stats().Batches; batches != NumDraws {
+			fail("interleaved draws: got %d batches, expected %d", batches, NumDraws)
+		}
+	})
+}
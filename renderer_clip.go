@@ -0,0 +1,351 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// maxDrawVertices is the maximum number of vertices that can be
+// referenced by uint16 indices in a single DrawTrianglesShader call.
+const maxDrawVertices = 1 << 16
+
+// SetClipRect restricts all the following draws to the given rect, relative to the
+// target's origin. This works like drawing to a subimage, but without changing the
+// coordinates, and it applies to shapes, batches, masks and effects drawn to any target.
+// The rect is always given in target coordinates, so it's not affected by [Renderer.SetGeoM]().
+//
+// Draws are clipped on the CPU by cutting their triangles to the rect, which gives the
+// same results as drawing to a subimage while preserving batching. Draws that fall fully
+// outside the rect are skipped, see [Stats].ClippedDraws. Internal offscreens are never
+// clipped, so effects are computed in full and only their final results are clipped.
+//
+// The clip rect is part of the state saved by [Renderer.Push](), which makes it easy to
+// clip nested UI panels:
+//
+//	func drawPanel(r *shapes.Renderer, target *ebiten.Image, panel image.Rectangle) {
+//		r.Push()
+//		defer r.Pop()
+//		if clip, ok := r.GetClipRect(); ok {
+//			panel = panel.Intersect(clip)
+//		}
+//		r.SetClipRect(panel)
+//		// ... draw the panel contents ...
+//	}
+func (r *Renderer) SetClipRect(rect image.Rectangle) {
+	r.clipRect = rect.Canon()
+	r.hasClip = true
+}
+
+// GetClipRect returns the rect set with [Renderer.SetClipRect](), and
+// whether it's enabled.
+func (r *Renderer) GetClipRect() (image.Rectangle, bool) {
+	return r.clipRect, r.hasClip
+}
+
+// ResetClipRect disables the clip rect set with [Renderer.SetClipRect]().
+func (r *Renderer) ResetClipRect() {
+	r.clipRect = image.Rectangle{}
+	r.hasClip = false
+}
+
+// clipping returns whether the next draw will be clipped.
+func (r *Renderer) clipping() bool {
+	return r.hasClip && r.internalPasses == 0
+}
+
+// drawClipped draws the triangles that fall within the clip rect, cutting the
+// ones that cross its edges. Vertex attributes are interpolated linearly, like
+// the GPU does, so the results match drawing to a subimage.
+func (r *Renderer) drawClipped(target *ebiten.Image, vertices []ebiten.Vertex, indices []uint16, shader *ebiten.Shader) {
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	clip := [4]float32{
+		dstOX + float32(r.clipRect.Min.X), dstOY + float32(r.clipRect.Min.Y),
+		dstOX + float32(r.clipRect.Max.X), dstOY + float32(r.clipRect.Max.Y),
+	}
+
+	// fast paths for draws fully inside or outside the clip rect
+	switch clipTest(verticesBounds(vertices), clip) {
+	case clipInside:
+		r.submitTriangles(target, vertices, indices, shader)
+		return
+	case clipOutside:
+		r.stats.stats.ClippedDraws += 1
+		return
+	}
+
+	outVertices, outIndices := r.clipVertices[:0], r.clipIndices[:0]
+	remap := r.resetClipRemap(len(vertices))
+	var submitted bool
+	for i := 0; i+2 < len(indices); i += 3 {
+		tri := [3]uint16{indices[i], indices[i+1], indices[i+2]}
+		var triBounds [4]float32
+		for j, index := range tri {
+			v := &vertices[index]
+			if j == 0 {
+				triBounds = [4]float32{v.DstX, v.DstY, v.DstX, v.DstY}
+			} else {
+				triBounds = expandBounds(triBounds, v.DstX, v.DstY)
+			}
+		}
+		test := clipTest(triBounds, clip)
+		if test == clipOutside {
+			continue
+		}
+
+		// flush before running out of indexable vertices
+		if len(outVertices)+clipMaxPolygon > maxDrawVertices {
+			r.submitTriangles(target, outVertices, outIndices, shader)
+			submitted = true
+			outVertices, outIndices = outVertices[:0], outIndices[:0]
+			remap = r.resetClipRemap(len(vertices))
+		}
+
+		if test == clipInside {
+			for _, index := range tri {
+				if remap[index] < 0 {
+					remap[index] = int32(len(outVertices))
+					outVertices = append(outVertices, vertices[index])
+				}
+				outIndices = append(outIndices, uint16(remap[index]))
+			}
+			continue
+		}
+
+		var polyBuffer [clipMaxPolygon]ebiten.Vertex
+		poly := clipTriangle(vertices[tri[0]], vertices[tri[1]], vertices[tri[2]], clip, polyBuffer[:0])
+		base := uint16(len(outVertices))
+		outVertices = append(outVertices, poly...)
+		for j := 1; j+1 < len(poly); j++ {
+			outIndices = append(outIndices, base, base+uint16(j), base+uint16(j+1))
+		}
+	}
+	r.clipVertices, r.clipIndices = outVertices, outIndices
+
+	if len(outIndices) > 0 {
+		r.submitTriangles(target, outVertices, outIndices, shader)
+	} else if !submitted {
+		r.stats.stats.ClippedDraws += 1
+	}
+}
+
+func (r *Renderer) resetClipRemap(n int) []int32 {
+	r.clipRemap = slices.Grow(r.clipRemap[:0], n)[:n]
+	for i := range r.clipRemap {
+		r.clipRemap[i] = -1
+	}
+	return r.clipRemap
+}
+
+// Results of [clipTest]().
+const (
+	clipPartial = iota
+	clipInside
+	clipOutside
+)
+
+// clipMaxPolygon is the maximum number of vertices of a triangle
+// clipped by a rect: one more for each of the rect's edges.
+const clipMaxPolygon = 3 + 4
+
+// clipTest returns whether the given bounds (minX, minY, maxX, maxY) are fully
+// inside, outside or partially inside the clip rect. Bounds that only touch the
+// rect's edges are considered outside, as they can't cover any pixel center.
+func clipTest(bounds, clip [4]float32) int {
+	if bounds[2] <= clip[0] || bounds[0] >= clip[2] || bounds[3] <= clip[1] || bounds[1] >= clip[3] {
+		return clipOutside
+	}
+	if bounds[0] >= clip[0] && bounds[2] <= clip[2] && bounds[1] >= clip[1] && bounds[3] <= clip[3] {
+		return clipInside
+	}
+	return clipPartial
+}
+
+// verticesBounds returns the bounds (minX, minY, maxX, maxY) of the
+// vertices' destination coordinates.
+func verticesBounds(vertices []ebiten.Vertex) [4]float32 {
+	if len(vertices) == 0 {
+		return [4]float32{}
+	}
+	bounds := [4]float32{vertices[0].DstX, vertices[0].DstY, vertices[0].DstX, vertices[0].DstY}
+	for i := 1; i < len(vertices); i++ {
+		bounds = expandBounds(bounds, vertices[i].DstX, vertices[i].DstY)
+	}
+	return bounds
+}
+
+func expandBounds(bounds [4]float32, x, y float32) [4]float32 {
+	return [4]float32{min(bounds[0], x), min(bounds[1], y), max(bounds[2], x), max(bounds[3], y)}
+}
+
+// clipTriangle clips the triangle to the clip rect (minX, minY, maxX, maxY) with
+// the Sutherland-Hodgman algorithm, appending the resulting convex polygon to out.
+// The polygon can have up to [clipMaxPolygon] vertices, or none at all.
+func clipTriangle(v0, v1, v2 ebiten.Vertex, clip [4]float32, out []ebiten.Vertex) []ebiten.Vertex {
+	var bufferA, bufferB [clipMaxPolygon]ebiten.Vertex
+	poly := append(bufferA[:0], v0, v1, v2)
+	next := bufferB[:0]
+	for edge := range 4 {
+		next = clipPolygonEdge(poly, edge, clip[edge], next[:0])
+		poly, next = next, poly
+		if len(poly) == 0 {
+			break
+		}
+	}
+	return append(out, poly...)
+}
+
+// clipPolygonEdge clips the convex polygon against one of the rect edges
+// (0: min x, 1: min y, 2: max x, 3: max y), appending the result to out.
+func clipPolygonEdge(poly []ebiten.Vertex, edge int, bound float32, out []ebiten.Vertex) []ebiten.Vertex {
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		distA, distB := clipEdgeDist(&a, edge, bound), clipEdgeDist(&b, edge, bound)
+		if distA >= 0 {
+			out = append(out, a)
+		}
+		if (distA >= 0) != (distB >= 0) {
+			v := lerpVertex(a, b, distA/(distA-distB))
+			if edge%2 == 0 {
+				v.DstX = bound // avoid precision errors
+			} else {
+				v.DstY = bound
+			}
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// clipEdgeDist returns the distance from the vertex to the given
+// edge of the clip rect, positive on the inner side.
+func clipEdgeDist(v *ebiten.Vertex, edge int, bound float32) float32 {
+	switch edge {
+	case 0:
+		return v.DstX - bound
+	case 1:
+		return v.DstY - bound
+	case 2:
+		return bound - v.DstX
+	default:
+		return bound - v.DstY
+	}
+}
+
+// lerpVertex linearly interpolates all the vertex attributes.
+func lerpVertex(a, b ebiten.Vertex, t float32) ebiten.Vertex {
+	lerp := func(x, y float32) float32 { return x + (y-x)*t }
+	return ebiten.Vertex{
+		DstX: lerp(a.DstX, b.DstX), DstY: lerp(a.DstY, b.DstY),
+		SrcX: lerp(a.SrcX, b.SrcX), SrcY: lerp(a.SrcY, b.SrcY),
+		ColorR: lerp(a.ColorR, b.ColorR), ColorG: lerp(a.ColorG, b.ColorG),
+		ColorB: lerp(a.ColorB, b.ColorB), ColorA: lerp(a.ColorA, b.ColorA),
+		Custom0: lerp(a.Custom0, b.Custom0), Custom1: lerp(a.Custom1, b.Custom1),
+		Custom2: lerp(a.Custom2, b.Custom2), Custom3: lerp(a.Custom3, b.Custom3),
+	}
+}
+
+// clipLayer is an offscreen layer started by [Renderer.BeginClip]() or
+// [Renderer.BeginClipMask](), waiting for its matching [Renderer.EndClip]().
+type clipLayer struct {
+	target       *ebiten.Image
+	layer        *OffscreenLease
+	coverage     *OffscreenLease
+	rect         image.Rectangle // relative to the target's origin
+	prevClipRect image.Rectangle
+	prevHasClip  bool
+}
+
+// BeginClip starts a clip layer for the given target, and returns an offscreen where
+// the draws that must be clipped to the given shape have to be made. [Renderer.EndClip]()
+// composites the layer into the target, showing only the parts inside the shape, with
+// the shape's edges antialiased like [Renderer.DrawSDF](). This is meant for whole UI
+// subtrees, like a scrolling list inside a rounded card:
+//
+//	shape := shapes.SDFBox(card.X+8, card.Y+8, card.W-16, card.H-16).Round(8)
+//	layer := renderer.BeginClip(screen, shape)
+//	for _, item := range list.VisibleItems() {
+//		item.Draw(renderer, layer) // same coordinates as on the screen
+//	}
+//	renderer.EndClip()
+//
+// The returned offscreen uses the same coordinates as the target, and it can be used
+// with any renderer function, with Ebitengine's own draw functions, or as the target of
+// a nested BeginClip() call. The shape is positioned like any other draw, so it's affected
+// by [Renderer.SetGeoM](). Until EndClip(), the clip rect is also restricted to the shape
+// bounds, so draws outside the shape are skipped. See [Renderer.SetClipRect]().
+//
+// The layer and the shape coverage are leased from the renderer's [OffscreenPool], and
+// released on EndClip(). When clipping to rects, [Renderer.SetClipRect]() is cheaper.
+func (r *Renderer) BeginClip(target *ebiten.Image, shape *SDF) *ebiten.Image {
+	const margin = 1.0 // same as DrawSDF
+	minX, minY, maxX, maxY := shape.Bounds()
+	layer, coverage := r.beginClipLayer(target, r.targetRect(minX-margin, minY-margin, maxX+margin, maxY+margin))
+	r.Push()
+	r.SetColor(color.White)
+	r.SetBlend(ebiten.BlendSourceOver)
+	r.DrawSDF(coverage, shape)
+	r.Pop()
+	return layer
+}
+
+// BeginClipMask is like [Renderer.BeginClip](), but the draws are clipped to the given
+// alpha mask, placed at (ox, oy). Like [Renderer.MaskAt](), but for any number of draws.
+func (r *Renderer) BeginClipMask(target, mask *ebiten.Image, ox, oy float32) *ebiten.Image {
+	w, h := rectSizeF32(mask.Bounds())
+	layer, coverage := r.beginClipLayer(target, r.targetRect(ox, oy, ox+w, oy+h))
+	r.Push()
+	r.SetBlend(ebiten.BlendSourceOver)
+	r.Scale(coverage, mask, ox, oy, 1.0, false)
+	r.Pop()
+	return layer
+}
+
+// EndClip composites the layer started by the last [Renderer.BeginClip]() or
+// [Renderer.BeginClipMask]() call into its target, using the renderer's blend,
+// and restores the previous clip rect. The function panics if there's no matching
+// BeginClip() call.
+func (r *Renderer) EndClip() {
+	if len(r.clipLayers) == 0 {
+		panic("EndClip() without matching BeginClip()")
+	}
+	clip := r.clipLayers[len(r.clipLayers)-1]
+	r.clipLayers = r.clipLayers[:len(r.clipLayers)-1]
+	r.clipRect, r.hasClip = clip.prevClipRect, clip.prevHasClip
+
+	// the layer is already in target space, so the GeoM must not be applied,
+	// and only the rect that could have been drawn needs to be composited
+	r.Push()
+	r.ResetGeoM()
+	r.SetClipRect(clip.rect)
+	r.SetSourceClamping(ClampNone)
+	r.MaskAt(clip.target, clip.layer.Image(), clip.coverage.Image(), 0, 0, 0, 0)
+	r.Pop()
+	clip.layer.Release()
+	clip.coverage.Release()
+}
+
+// beginClipLayer leases the layer and coverage offscreens for the given target
+// rect and pushes the clip layer. Both offscreens cover the target from its origin
+// to the rect's bottom-right corner, so they share the target coordinates.
+func (r *Renderer) beginClipLayer(target *ebiten.Image, rect image.Rectangle) (layer, coverage *ebiten.Image) {
+	targetBounds := target.Bounds()
+	rect = rect.Intersect(image.Rect(0, 0, targetBounds.Dx(), targetBounds.Dy()))
+	if r.hasClip {
+		rect = rect.Intersect(r.clipRect)
+	}
+	w, h := max(rect.Max.X, 1), max(rect.Max.Y, 1)
+	clip := clipLayer{
+		target:       target,
+		layer:        r.pool.Lease(w, h, true),
+		coverage:     r.pool.Lease(w, h, true),
+		rect:         rect,
+		prevClipRect: r.clipRect,
+		prevHasClip:  r.hasClip,
+	}
+	r.clipLayers = append(r.clipLayers, clip)
+	r.SetClipRect(rect)
+	return clip.layer.Image(), clip.coverage.Image()
+}
@@ -0,0 +1,283 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// go test -run ^TestClipRect$ . -count 1
+func TestClipRect(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.RGBA{16, 16, 24, 255})
+		bounds := canvas.Bounds()
+		w, h := float32(bounds.Dx()), float32(bounds.Dy())
+		scroll := ctx.DistAnim(200, 0.2)
+
+		// a scrolling list inside a panel that follows the last left click
+		panel := image.Rectangle{Min: ctx.LeftClick, Max: ctx.LeftClick}.Inset(-90)
+		ctx.Renderer.SetColor(color.RGBA{40, 40, 60, 255})
+		ctx.Renderer.DrawRect(canvas, panel.Inset(-4), 6)
+
+		ctx.Renderer.Push()
+		ctx.Renderer.SetClipRect(panel)
+		for i := range 12 {
+			y := float32(panel.Min.Y) + float32(i)*36 - float32(scroll)
+			ctx.Renderer.SetColor(color.RGBA{80, 200, 255, 255})
+			ctx.Renderer.DrawArea(canvas, float32(panel.Min.X)+8, y+4, 164, 28, -8)
+			ctx.Renderer.SetColor(color.RGBA{200, 120, 40, 255})
+			ctx.Renderer.DrawCircle(canvas, float32(panel.Min.X)+24, y+18, 9)
+		}
+		ctx.Renderer.Pop()
+
+		// a circle clipped to the left half of the screen
+		ctx.Renderer.Push()
+		ctx.Renderer.SetClipRect(image.Rect(0, 0, int(w/2), int(h)))
+		ctx.Renderer.SetColor(color.RGBA{240, 240, 240, 255})
+		ctx.Renderer.DrawCircle(canvas, w/2, h-60, 40)
+		ctx.Renderer.Pop()
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClipTriangle(t *testing.T) {
+	clip := [4]float32{0, 0, 10, 10}
+	vertex := func(x, y float32) ebiten.Vertex {
+		return ebiten.Vertex{DstX: x, DstY: y, SrcX: x * 2, SrcY: y * 2, ColorA: 1, Custom0: x + y}
+	}
+	tests := []struct {
+		name string
+		tri  [3]ebiten.Vertex
+		area float64
+	}{
+		{"inside", [3]ebiten.Vertex{vertex(1, 1), vertex(5, 1), vertex(1, 5)}, 8},
+		{"covering", [3]ebiten.Vertex{vertex(-10, -10), vertex(30, -10), vertex(-10, 30)}, 100},
+		{"diamond corner", [3]ebiten.Vertex{vertex(5, -5), vertex(15, 5), vertex(5, 15)}, 50},
+		{"crossing top", [3]ebiten.Vertex{vertex(-5, 5), vertex(5, -5), vertex(15, 5)}, 50},
+		{"outside", [3]ebiten.Vertex{vertex(20, 20), vertex(30, 20), vertex(20, 30)}, 0},
+	}
+	for _, test := range tests {
+		poly := clipTriangle(test.tri[0], test.tri[1], test.tri[2], clip, nil)
+		if len(poly) > clipMaxPolygon {
+			t.Errorf("%s: got %d vertices, max is %d", test.name, len(poly), clipMaxPolygon)
+		}
+		var area float64
+		for i, v := range poly {
+			next := poly[(i+1)%len(poly)]
+			area += float64(v.DstX*next.DstY - next.DstX*v.DstY)
+			if v.DstX < clip[0] || v.DstY < clip[1] || v.DstX > clip[2] || v.DstY > clip[3] {
+				t.Errorf("%s: vertex (%v, %v) outside the clip rect", test.name, v.DstX, v.DstY)
+			}
+			if v.SrcX != v.DstX*2 || v.SrcY != v.DstY*2 || v.ColorA != 1 || math.Abs(float64(v.Custom0-v.DstX-v.DstY)) > 1e-4 {
+				t.Errorf("%s: wrong attributes interpolation at (%v, %v)", test.name, v.DstX, v.DstY)
+			}
+		}
+		if area = math.Abs(area) / 2; math.Abs(area-test.area) > 1e-4 {
+			t.Errorf("%s: expected area %v, got %v", test.name, test.area, area)
+		}
+	}
+}
+
+// go test -run ^TestClipRectMatchesDraws$ . -count 1
+func TestClipRectMatchesDraws(t *testing.T) {
+	const w, h = 96, 72
+	const offX, offY = 21, 13
+	clip := image.Rect(30, 18, 70, 60)
+
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	var mask, source *ebiten.Image
+	cases := []struct {
+		name string
+		draw func(r *Renderer, target *ebiten.Image)
+	}{
+		{"DrawArea", func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, 10, 8, 76, 56, 6) }},
+		{"DrawCircle", func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, 48, 36, 30) }},
+		{"DrawLine", func(r *Renderer, target *ebiten.Image) { r.DrawLine(target, 4, 70, 90, 4, 6) }},
+		{"DrawTriangleColors", func(r *Renderer, target *ebiten.Image) {
+			r.DrawTriangleColors(target, 10, 10, 90, 30, 30, 70, 4, red, color.White, blue)
+		}},
+		{"DrawQuad", func(r *Renderer, target *ebiten.Image) {
+			r.DrawQuad(target, [4]PointF32{{X: 14, Y: 10}, {X: 80, Y: 18}, {X: 70, Y: 64}, {X: 22, Y: 56}}, 2)
+		}},
+		{"DrawSDF", func(r *Renderer, target *ebiten.Image) {
+			r.DrawSDF(target, SDFSmoothUnion(SDFCircle(36, 36, 24), SDFBox(56, 30, 36, 30), 8))
+		}},
+		{"ShapeBatch", func(r *Renderer, target *ebiten.Image) {
+			batch := r.NewShapeBatch()
+			for i := range 10 {
+				batch.AddCircle(float32(8+i*9), float32(10+i*6), 8)
+			}
+			batch.AddRing(48, 36, 10, 20)
+			batch.Flush(target)
+		}},
+		{"ApplyBlur2", func(r *Renderer, target *ebiten.Image) { r.ApplyBlur2(target, mask, 20, 10, 8, 1) }},
+		{"ApplyGlow", func(r *Renderer, target *ebiten.Image) { r.ApplyGlow(target, mask, 20, 10, 6, 6, 0.1, 0.9, 0.5) }},
+		{"Gradient", func(r *Renderer, target *ebiten.Image) { r.Gradient(target, mask, 20, 10, red, blue, 8, 0.5, 1) }},
+		{"MapProjective", func(r *Renderer, target *ebiten.Image) {
+			r.MapProjective(target, source, [4]PointF32{{X: 4, Y: 6}, {X: 90, Y: 2}, {X: 80, Y: 70}, {X: 10, Y: 60}})
+		}},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		mask = r.NewCircle(24)
+		source = r.NewSimpleGradient(32, 28, red, blue, 0.5)
+
+		// draw on offset subimages to check that the clip
+		// rect is relative to the target's origin
+		refParent := ebiten.NewImage(w+offX*2, h+offY*2)
+		clipParent := ebiten.NewImage(w+offX*2, h+offY*2)
+		subRect := image.Rect(offX, offY, offX+w, offY+h)
+		ref := refParent.SubImage(subRect).(*ebiten.Image)
+		clipped := clipParent.SubImage(subRect).(*ebiten.Image)
+		refPix := make([]byte, w*h*4)
+		clipPix := make([]byte, w*h*4)
+		for _, c := range cases {
+			refParent.Clear()
+			clipParent.Clear()
+			c.draw(r, ref)
+			r.SetClipRect(clip)
+			c.draw(r, clipped)
+			r.ResetClipRect()
+			ref.ReadPixels(refPix)
+			clipped.ReadPixels(clipPix)
+
+			// the clipped draw must match the reference inside the
+			// clip rect, and leave everything else untouched
+			for y := range h {
+				for x := range w {
+					if !image.Pt(x, y).In(clip) {
+						i := (y*w + x) * 4
+						copy(refPix[i:i+4], []byte{0, 0, 0, 0})
+					}
+				}
+			}
+			if msg := comparePixels(refPix, clipPix, w, 2); msg != "" {
+				fail("%s: %s", c.name, msg)
+			} else if isTransparent(refPix) {
+				fail("%s: reference output is fully transparent", c.name)
+			}
+		}
+
+		// draws fully outside the clip rect must be skipped
+		r.SetClipRect(clip)
+		r.ResetStats()
+		r.DrawCircle(clipped, 10, 10, 8)
+		r.DrawArea(clipped, 72, 62, 20, 8, 0)
+		r.DrawCircle(clipped, 48, 36, 8)
+		if stats := r.Stats(); stats.DrawCalls != 1 || stats.ClippedDraws != 2 {
+			fail("expected 1 draw call and 2 clipped draws")
+		}
+		r.ResetClipRect()
+	})
+}
+
+// go test -run ^TestClipLayer$ . -count 1
+func TestClipLayer(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.RGBA{16, 16, 24, 255})
+		scroll := float32(ctx.DistAnim(200, 0.2))
+
+		// a scrolling list inside a rounded card that follows the last left click
+		x, y := ctx.LeftClickF32()
+		card := SDFBox(x-90+12, y-120+12, 180-24, 240-24).Round(12)
+		ctx.Renderer.SetColor(color.RGBA{40, 40, 60, 255})
+		ctx.Renderer.DrawSDF(canvas, card)
+		layer := ctx.Renderer.BeginClip(canvas, card)
+		for i := range 12 {
+			itemY := y - 120 + float32(i)*36 - scroll
+			ctx.Renderer.SetColor(color.RGBA{80, 200, 255, 255})
+			ctx.Renderer.DrawArea(layer, x-90, itemY+4, 180, 28, 0)
+			ctx.Renderer.SetColor(color.RGBA{200, 120, 40, 255})
+			ctx.Renderer.DrawCircle(layer, x-70, itemY+18, 9)
+		}
+		ctx.Renderer.EndClip()
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestClipLayerMatchesDraws$ . -count 1
+func TestClipLayerMatchesDraws(t *testing.T) {
+	const w, h = 96, 72
+	const offX, offY = 21, 13
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		mask := r.NewCircle(20)
+		refParent := ebiten.NewImage(w+offX*2, h+offY*2)
+		clipParent := ebiten.NewImage(w+offX*2, h+offY*2)
+		subRect := image.Rect(offX, offY, offX+w, offY+h)
+		ref := refParent.SubImage(subRect).(*ebiten.Image)
+		clipped := clipParent.SubImage(subRect).(*ebiten.Image)
+		refPix := make([]byte, w*h*4)
+		clipPix := make([]byte, w*h*4)
+		compare := func(name string) {
+			ref.ReadPixels(refPix)
+			clipped.ReadPixels(clipPix)
+			if msg := comparePixels(refPix, clipPix, w, 2); msg != "" {
+				fail("%s: %s", name, msg)
+			} else if isTransparent(refPix) {
+				fail("%s: reference output is fully transparent", name)
+			}
+		}
+
+		// filling a clip layer must match drawing the shape directly
+		shape := SDFSmoothUnion(SDFCircle(36, 36, 20), SDFBox(50, 20, 36, 30).Round(4), 6)
+		var translation ebiten.GeoM
+		translation.Translate(-6, 4)
+		for _, geoM := range []ebiten.GeoM{{}, translation} {
+			refParent.Clear()
+			clipParent.Clear()
+			r.SetGeoM(geoM)
+			r.DrawSDF(ref, shape)
+			layer := r.BeginClip(clipped, shape)
+			r.DrawArea(layer, -10, -10, w+20, h+20, 0)
+			r.EndClip()
+			r.ResetGeoM()
+			compare("BeginClip")
+		}
+
+		refParent.Clear()
+		clipParent.Clear()
+		r.Scale(ref, mask, 20, 10, 1, false)
+		layer := r.BeginClipMask(clipped, mask, 20, 10)
+		r.DrawArea(layer, 0, 0, w, h, 0)
+		r.EndClip()
+		compare("BeginClipMask")
+
+		// nested clips must intersect, and the outer clip rect must apply
+		refParent.Clear()
+		clipParent.Clear()
+		r.SetClipRect(image.Rect(0, 0, 48, h))
+		r.DrawSDF(ref, SDFCircle(52, 36, 20))
+		outer := r.BeginClip(clipped, SDFBox(10, 10, 76, 52))
+		inner := r.BeginClip(outer, SDFCircle(52, 36, 20))
+		r.DrawArea(inner, 0, 0, w, h, 0)
+		r.EndClip()
+		r.EndClip()
+		if clipRect, hasClip := r.GetClipRect(); !hasClip || clipRect != image.Rect(0, 0, 48, h) {
+			fail("EndClip() didn't restore the clip rect")
+		}
+		r.ResetClipRect()
+		compare("nested BeginClip")
+
+		if leases := r.OffscreenPool().Leases(); leases != 0 {
+			fail("EndClip() didn't release the layer leases")
+		}
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for unbalanced EndClip()")
+		}
+	}()
+	NewRenderer().EndClip()
+}
@@ -65,7 +65,7 @@ func (r *Renderer) Gradient(target, mask *ebiten.Image, ox, oy float32, from, to
 	r.SetColorF32(float32(toOklab[0]), float32(toOklab[1]), float32(toOklab[2]), float32(toF64[3]))
 	r.setFlatCustomVAs(float32(fromOklab[0]), float32(fromOklab[1]), float32(fromOklab[2]), float32(fromF64[3]))
 
-	r.opts.Uniforms["Area"] = [4]float32{ox, oy, srcWidth, srcHeight}
+	r.opts.Uniforms["Area"] = [4]float32{srcMinX, srcMinY, srcWidth, srcHeight}
 	r.opts.Uniforms["DirRadians"] = dirRadians
 	r.opts.Uniforms["NumSteps"] = numSteps
 	r.opts.Uniforms["CurveFactor"] = curveFactor
@@ -78,7 +78,7 @@ func (r *Renderer) Gradient(target, mask *ebiten.Image, ox, oy float32, from, to
 	// draw shader
 	r.opts.Images[0] = mask
 	ensureShaderGradientLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderGradient, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderGradient)
 	r.opts.Images[0] = nil
 	clear(r.opts.Uniforms)
 	r.SetColorF32(memo[0], memo[1], memo[2], memo[3])
@@ -110,15 +110,12 @@ func (r *Renderer) GradientRadial(target *ebiten.Image, cx, cy float32, from, to
 		panic("invalid radius values (radiuses must be equal or increasing)")
 	}
 
-	dstBounds := target.Bounds()
-	dstMinX, dstMinY := float32(dstBounds.Min.X), float32(dstBounds.Min.Y)
-	dstWidthF64, dstHeightF64 := float64(dstBounds.Dx()), float64(dstBounds.Dy())
+	// clamp to the target, as toRadius can be infinite
+	minX, minY, maxX, maxY := r.localTargetBounds(target)
 	cxF64, cyF64, toRadiusF64 := float64(cx), float64(cy), float64(toRadius)
-	ox, oy := float32(max(math.Floor(cxF64-toRadiusF64), 0)), float32(max(math.Floor(cyF64-toRadiusF64), 0))
-	fx, fy := float32(min(math.Ceil(cxF64+toRadiusF64), dstWidthF64)), float32(min(math.Ceil(cyF64+toRadiusF64), dstHeightF64))
-	minX, minY := dstMinX+ox, dstMinY+oy
-	maxX, maxY := dstMinX+fx, dstMinY+fy
-	r.setDstRectCoords(minX, minY, maxX, maxY)
+	minX, minY = max(float32(math.Floor(cxF64-toRadiusF64)), minX), max(float32(math.Floor(cyF64-toRadiusF64)), minY)
+	maxX, maxY = min(float32(math.Ceil(cxF64+toRadiusF64)), maxX), min(float32(math.Ceil(cyF64+toRadiusF64)), maxY)
+	r.setLocalRectCoords(target, minX, minY, maxX, maxY)
 
 	fromF64, toF64 := colorToF64(from), colorToF64(to)
 	memo := r.GetColorF32()
@@ -134,7 +131,7 @@ func (r *Renderer) GradientRadial(target *ebiten.Image, cx, cy float32, from, to
 
 	// draw shader
 	ensureShaderGradientRadialLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderGradientRadial, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderGradientRadial)
 	clear(r.opts.Uniforms)
 	r.SetColorF32(memo[0], memo[1], memo[2], memo[3])
 }
@@ -220,7 +217,7 @@ func (r *Renderer) ColorMix(target, base, over *ebiten.Image, x, y int, alpha, m
 	r.opts.Images[0] = base
 	r.opts.Images[1] = over
 	ensureShaderColorMixLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderColorMix, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderColorMix)
 	r.opts.Images[0] = nil
 	r.opts.Images[1] = nil
 }
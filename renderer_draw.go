@@ -2,6 +2,7 @@ package shapes
 
 import (
 	"image"
+	"image/color"
 	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -33,6 +34,8 @@ func (r *Renderer) DrawRect(target *ebiten.Image, rect image.Rectangle, rounding
 	r.DrawArea(target, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), rounding)
 }
 
+// DrawArea draws a smooth rectangle. Positive rounding values expand the rectangle
+// with rounded corners, while negative values round the corners within the given area.
 func (r *Renderer) DrawArea(target *ebiten.Image, ox, oy, w, h, rounding float32) {
 	if w < 0 {
 		w = -w
@@ -42,15 +45,55 @@ func (r *Renderer) DrawArea(target *ebiten.Image, ox, oy, w, h, rounding float32
 		h = -h
 		oy -= h
 	}
+	ox, oy, w, h, rounding = r.snapArea(ox, oy, w, h, rounding)
+	inRounding, outRounding := r.splitRounding(rounding)
+	if outRounding > 0 {
+		ox, oy = ox-outRounding, oy-outRounding
+		w, h = w+outRounding*2, h+outRounding*2
+	}
 	ensureShaderRectLoaded()
-	r.setFlatCustomVAs(ox, oy, w, h)
-	r.opts.Uniforms["Rounding"] = rounding
+	r.setFlatCustomVAs(w, h, min(inRounding, min(w, h)/2)+outRounding, 0)
+	r.setSoftEdgeUniform()
 	r.DrawRectShader(target, ox, oy, w, h, 0, 0, shaderRect)
 	clear(r.opts.Uniforms)
 }
 
 // DrawLine draws a smooth line between the given two points, with rounded ends.
 func (r *Renderer) DrawLine(target *ebiten.Image, ox, oy, fx, fy float64, thickness float64) {
+	ox, oy, fx, fy, thickness = r.snapLine(ox, oy, fx, fy, thickness)
+	length := math.Hypot(fx-ox, fy-oy)
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	srcCorners := lineSrcCorners(length, thickness)
+	for i, pt := range lineCorners(ox, oy, fx, fy, thickness) {
+		r.vertices[i].DstX = dstOX + pt.X
+		r.vertices[i].DstY = dstOY + pt.Y
+		r.vertices[i].SrcX = srcCorners[i].X
+		r.vertices[i].SrcY = srcCorners[i].Y
+	}
+	r.setFlatCustomVAs(float32(length), float32(thickness), 0, 0)
+
+	// draw shader
+	ensureShaderLineLoaded()
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderLine)
+	clear(r.opts.Uniforms)
+}
+
+// lineSrcCorners returns the source coordinates matching [lineCorners](),
+// relative to the line start and with the x axis going towards the end.
+func lineSrcCorners(length, thickness float64) [4]PointF32 {
+	end, halfThick := float32(length+thickness/2), float32(thickness/2)
+	return [4]PointF32{
+		{X: -halfThick, Y: halfThick},
+		{X: end, Y: halfThick},
+		{X: end, Y: -halfThick},
+		{X: -halfThick, Y: -halfThick},
+	}
+}
+
+// lineCorners returns the corners of the oriented rect containing
+// the line, including the rounded ends.
+func lineCorners(ox, oy, fx, fy, thickness float64) [4]PointF32 {
 	vdx, vdy := fx-ox, fy-oy // non-normalized vector
 	vpx, vpy := -vdy, vdx    // perpendicular vector
 	length := math.Hypot(vdx, vdy)
@@ -66,28 +109,101 @@ func (r *Renderer) DrawLine(target *ebiten.Image, ox, oy, fx, fy float64, thickn
 
 	// compute bounding vertices applying the perpendicular offset
 	svpx, svpy := vpx*scale, vpy*scale
-	r.vertices[0].DstX = float32(box + svpx)
-	r.vertices[0].DstY = float32(boy + svpy)
-	r.vertices[1].DstX = float32(bfx + svpx)
-	r.vertices[1].DstY = float32(bfy + svpy)
-	r.vertices[2].DstX = float32(bfx - svpx)
-	r.vertices[2].DstY = float32(bfy - svpy)
-	r.vertices[3].DstX = float32(box - svpx)
-	r.vertices[3].DstY = float32(boy - svpy)
-
-	r.setFlatCustomVAs(float32(ox), float32(oy), float32(fx), float32(fy))
-	r.opts.Uniforms["Thickness"] = float32(thickness)
+	return [4]PointF32{
+		{X: float32(box + svpx), Y: float32(boy + svpy)},
+		{X: float32(bfx + svpx), Y: float32(bfy + svpy)},
+		{X: float32(bfx - svpx), Y: float32(bfy - svpy)},
+		{X: float32(box - svpx), Y: float32(boy - svpy)},
+	}
+}
+
+// DrawTaperedLine draws a smooth line between the given two points, with rounded
+// ends and thickness and color linearly interpolated from start to end. The
+// renderer's color is ignored. Useful for brush-like strokes, tracers and similar.
+//
+// If one end's rounding fully contains the other (|startThick - endThick| >= 2*length),
+// only the biggest end circle is drawn.
+func (r *Renderer) DrawTaperedLine(target *ebiten.Image, ox, oy, fx, fy, startThick, endThick float64, startClr, endClr color.Color) {
+	if startThick < 0 || endThick < 0 {
+		panic("startThick < 0 || endThick < 0")
+	}
+	if startThick == 0 && endThick == 0 {
+		return // nothing to draw
+	}
+
+	// vertices 2-5 are at the end side of the line
+	outline := taperedLineOutline(ox, oy, fx, fy, startThick, endThick)
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	startF32, endF32 := ColorToF32(startClr), ColorToF32(endClr)
+	vertices := r.getAuxVertices(8)
+	for i, pt := range outline {
+		clr := startF32
+		if i >= 2 && i < 6 {
+			clr = endF32
+		}
+		v := &vertices[i]
+		v.SrcX, v.SrcY = pt.X, pt.Y
+		v.DstX, v.DstY = dstOX+pt.X, dstOY+pt.Y
+		v.ColorR, v.ColorG, v.ColorB, v.ColorA = clr[0], clr[1], clr[2], clr[3]
+		v.Custom0, v.Custom1 = float32(ox), float32(oy)
+		v.Custom2, v.Custom3 = float32(fx), float32(fy)
+	}
 
 	// draw shader
-	ensureShaderLineLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderLine, &r.opts)
+	ensureShaderTaperedLineLoaded()
+	r.opts.Uniforms["Radiuses"] = [2]float32{float32(startThick / 2.0), float32(endThick / 2.0)}
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, vertices, taperedLineIndices, shaderTaperedLine)
+	clear(r.opts.Uniforms)
+}
+
+// taperedLineOutline returns the geometry of [Renderer.DrawTaperedLine](), with
+// vertices 0-3 going along the positive perpendicular side and 4-7 coming back
+// along the negative side.
+func taperedLineOutline(ox, oy, fx, fy, startThick, endThick float64) [8]PointF32 {
+	startRadius, endRadius := startThick/2.0, endThick/2.0
+	vdx, vdy := fx-ox, fy-oy
+	length := math.Hypot(vdx, vdy)
+	if length == 0 {
+		vdx, vdy = 1, 0
+	} else {
+		vdx, vdy = vdx/length, vdy/length
+	}
+	vpx, vpy := -vdy, vdx
+
+	// compute distances along the axis for the caps, and
+	// the perpendicular offset for the whole shape
+	startExt := max(startRadius, endRadius-length)
+	endExt := max(endRadius, startRadius-length)
+	perpExt := max(startRadius, endRadius)
+	axis := [4]float64{-startExt, 0, length, length + endExt}
+
+	var outline [8]PointF32
+	for i, t := range axis {
+		bx, by := ox+vdx*t, oy+vdy*t
+		side := [2]int{i, 7 - i}
+		for j, sign := range [2]float64{+1, -1} {
+			outline[side[j]] = PointF32{X: float32(bx + vpx*perpExt*sign), Y: float32(by + vpy*perpExt*sign)}
+		}
+	}
+	return outline
+}
+
+var taperedLineIndices = []uint16{
+	0, 1, 6, 0, 6, 7, // start cap
+	1, 2, 5, 1, 5, 6, // body
+	2, 3, 4, 2, 4, 5, // end cap
 }
 
 func (r *Renderer) DrawCircle(target *ebiten.Image, cx, cy, radius float32) {
-	r.setDstRectCoords(cx-radius, cy-radius, cx+radius, cy+radius)
+	cx, cy = r.snapCenter(cx, cy)
+	radius = r.snapRadius(radius)
+	r.setLocalRectCoords(target, cx-radius, cy-radius, cx+radius, cy+radius)
 	ensureShaderCircleLoaded()
 	r.setFlatCustomVAs(cx, cy, radius, 0.0)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderCircle, &r.opts)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderCircle)
+	clear(r.opts.Uniforms)
 }
 
 func (r *Renderer) StrokeCircle(target *ebiten.Image, cx, cy, radius, thickness float32) {
@@ -103,10 +219,13 @@ func (r *Renderer) StrokeCircle(target *ebiten.Image, cx, cy, radius, thickness
 	}
 
 	hthickCeil := ceilF32(thickness / 2.0)
-	r.setDstRectCoords(cx-radius-hthickCeil, cy-radius-hthickCeil, cx+radius+hthickCeil, cy+radius+hthickCeil)
+	ext := radius + hthickCeil
+	r.setLocalRectCoords(target, cx-ext, cy-ext, cx+ext, cy+ext)
 	ensureShaderStrokeCircleLoaded()
 	r.setFlatCustomVAs(cx, cy, radius, thickness)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderStrokeCircle, &r.opts)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderStrokeCircle)
+	clear(r.opts.Uniforms)
 }
 
 // DrawRing draws a smooth ring at the given position. For ring segments
@@ -115,25 +234,32 @@ func (r *Renderer) DrawRing(target *ebiten.Image, cx, cy, inRadius, outRadius fl
 	if inRadius >= outRadius {
 		return // skip empty draws
 	}
-	r.setDstRectCoords(cx-outRadius, cy-outRadius, cx+outRadius, cy+outRadius)
+	cx, cy = r.snapCenter(cx, cy)
+	inRadius, outRadius = r.snapRadius(inRadius), r.snapRadius(outRadius)
+	r.setLocalRectCoords(target, cx-outRadius, cy-outRadius, cx+outRadius, cy+outRadius)
 	ensureShaderRingLoaded()
 	r.setFlatCustomVAs(cx, cy, outRadius, inRadius)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderRing, &r.opts)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderRing)
+	clear(r.opts.Uniforms)
 }
 
 // DrawRingSector draws a smooth ring segment. See [RadsRight] constants for
 // angle conventions and docs.
 //
-// Only outer rounding is supported at the moment.
+// Positive rounding values expand the sector with rounded corners, while negative values
+// round the corners within the original radiuses and sides.
 func (r *Renderer) DrawRingSector(target *ebiten.Image, cx, cy, inRadius, outRadius float32, startRads, endRads float64, rounding float32) {
 	if inRadius >= outRadius || outRadius < 0 || startRads == endRads {
 		return // skip empty draws
 	}
 	if inRadius <= 0 {
-		r.DrawCircle(target, cx, cy, outRadius)
+		r.DrawPie(target, cx, cy, outRadius, startRads, endRads, rounding)
+		return
 	}
 	if endRads >= startRads+2*math.Pi {
 		r.DrawRing(target, cx, cy, inRadius, outRadius)
+		return
 	}
 
 	startRads, endRads = normURads(startRads), normURads(endRads)
@@ -143,50 +269,47 @@ func (r *Renderer) DrawRingSector(target *ebiten.Image, cx, cy, inRadius, outRad
 // precondition: angles are normalized to [0, 2*pi)
 func (r *Renderer) internalDrawRingSector(target *ebiten.Image, cx, cy, inRadius, outRadius float32, startRads, endRads float64, rounding float32) {
 	pieMinX, pieMinY, pieMaxX, pieMaxY := ringSectorBounds(cx, cy, inRadius, outRadius, startRads, endRads)
-	if rounding != 0 {
-		r := abs(rounding)
-		pieMinX -= r
-		pieMinY -= r
-		pieMaxX += r
-		pieMaxY += r
+	delta := uradsDeltaCW(startRads, endRads)
+	sectorInRadius, sectorOutRadius, apexShift, dilation := ringSectorRoundingParams(inRadius, outRadius, delta/2.0, rounding)
+	if rounding > 0 {
+		pieMinX -= rounding
+		pieMinY -= rounding
+		pieMaxX += rounding
+		pieMaxY += rounding
 	}
 
-	dstOX, dstOY := rectOriginF32(target.Bounds())
-	r.vertices[0].DstX = dstOX + pieMinX
-	r.vertices[0].DstY = dstOY + pieMinY
-	r.vertices[1].DstX = dstOX + pieMaxX
-	r.vertices[1].DstY = dstOY + pieMinY
-	r.vertices[2].DstX = dstOX + pieMaxX
-	r.vertices[2].DstY = dstOY + pieMaxY
-	r.vertices[3].DstX = dstOX + pieMinX
-	r.vertices[3].DstY = dstOY + pieMaxY
+	r.setLocalRectCoords(target, pieMinX, pieMinY, pieMaxX, pieMaxY)
 
 	ensureShaderRingSectorLoaded()
-	delta := uradsDeltaCW(startRads, endRads)
 	centerDir := uradsAddCW(startRads, delta/2.0)
 	ws, wc := math.Sincos(delta / 2.0)
 	r.opts.Uniforms["WedgeNormal"] = [2]float32{float32(ws), float32(wc)}
-	r.opts.Uniforms["InRadius"] = inRadius
-	r.opts.Uniforms["Rounding"] = rounding
-	r.setFlatCustomVAs(cx, cy, float32(centerDir), outRadius)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderRingSector, &r.opts)
+	r.opts.Uniforms["InRadius"] = sectorInRadius
+	r.opts.Uniforms["Rounding"] = dilation
+	r.opts.Uniforms["ApexShift"] = apexShift
+	r.setFlatCustomVAs(cx, cy, float32(centerDir), sectorOutRadius)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderRingSector)
 	clear(r.opts.Uniforms)
 }
 
 // StrokeRingSector draws the outline of a smooth ring segment. See [RadsRight]
 // constants for angle conventions and docs.
 //
-// Only outer rounding is supported at the moment.
+// Positive rounding values expand the sector with rounded corners, while negative values
+// round the corners within the original radiuses and sides.
 func (r *Renderer) StrokeRingSector(target *ebiten.Image, cx, cy, inRadius, outRadius, thickness float32, startRads, endRads float64, rounding float32) {
 	if inRadius >= outRadius || outRadius < 0 || startRads == endRads || thickness <= 0 {
 		return // skip empty draws
 	}
 	if inRadius <= 0 {
-		r.StrokeCircle(target, cx, cy, outRadius, thickness)
+		r.StrokePie(target, cx, cy, outRadius, thickness, startRads, endRads, rounding)
+		return
 	}
 	if endRads >= startRads+2*math.Pi {
 		r.StrokeCircle(target, cx, cy, inRadius, thickness)
 		r.StrokeCircle(target, cx, cy, outRadius, thickness)
+		return
 	}
 
 	startRads, endRads = normURads(startRads), normURads(endRads)
@@ -196,38 +319,32 @@ func (r *Renderer) StrokeRingSector(target *ebiten.Image, cx, cy, inRadius, outR
 // precondition: angles are normalized to [0, 2*pi)
 func (r *Renderer) internalStrokeRingSector(target *ebiten.Image, cx, cy, inRadius, outRadius, thickness float32, startRads, endRads float64, rounding float32) {
 	pieMinX, pieMinY, pieMaxX, pieMaxY := ringSectorBounds(cx, cy, inRadius, outRadius, startRads, endRads)
-	if rounding != 0 {
-		r := abs(rounding)
-		pieMinX -= r
-		pieMinY -= r
-		pieMaxX += r
-		pieMaxY += r
+	delta := uradsDeltaCW(startRads, endRads)
+	sectorInRadius, sectorOutRadius, apexShift, dilation := ringSectorRoundingParams(inRadius, outRadius, delta/2.0, rounding)
+	if rounding > 0 {
+		pieMinX -= rounding
+		pieMinY -= rounding
+		pieMaxX += rounding
+		pieMaxY += rounding
 	}
 	pieMinX -= thickness / 2.0
 	pieMinY -= thickness / 2.0
 	pieMaxX += thickness / 2.0
 	pieMaxY += thickness / 2.0
 
-	dstOX, dstOY := rectOriginF32(target.Bounds())
-	r.vertices[0].DstX = dstOX + pieMinX
-	r.vertices[0].DstY = dstOY + pieMinY
-	r.vertices[1].DstX = dstOX + pieMaxX
-	r.vertices[1].DstY = dstOY + pieMinY
-	r.vertices[2].DstX = dstOX + pieMaxX
-	r.vertices[2].DstY = dstOY + pieMaxY
-	r.vertices[3].DstX = dstOX + pieMinX
-	r.vertices[3].DstY = dstOY + pieMaxY
+	r.setLocalRectCoords(target, pieMinX, pieMinY, pieMaxX, pieMaxY)
 
 	ensureShaderStrokeRingSectorLoaded()
-	delta := uradsDeltaCW(startRads, endRads)
 	centerDir := uradsAddCW(startRads, delta/2.0)
 	ws, wc := math.Sincos(delta / 2.0)
 	r.opts.Uniforms["WedgeNormal"] = [2]float32{float32(ws), float32(wc)}
-	r.opts.Uniforms["InRadius"] = inRadius
-	r.opts.Uniforms["Rounding"] = rounding
+	r.opts.Uniforms["InRadius"] = sectorInRadius
+	r.opts.Uniforms["Rounding"] = dilation
+	r.opts.Uniforms["ApexShift"] = apexShift
 	r.opts.Uniforms["Thickness"] = thickness
-	r.setFlatCustomVAs(cx, cy, float32(centerDir), outRadius)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderStrokeRingSector, &r.opts)
+	r.setFlatCustomVAs(cx, cy, float32(centerDir), sectorOutRadius)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderStrokeRingSector)
 	clear(r.opts.Uniforms)
 }
 
@@ -238,8 +355,8 @@ func (r *Renderer) internalStrokeRingSector(target *ebiten.Image, cx, cy, inRadi
 //   - startRads = RadsRight, endRads = RadsBottom will draw the bottom-right quarter circle pie.
 //   - startRads = RadsBottom, endRads = RadsRight will draw a pie missing the bottom-right quarter.
 //
-// Notice that the rounding parameter doesn't expand the shape beyond the radius, but it does expand
-// the side flat faces of the pie.
+// Positive rounding values expand the pie with rounded corners, while negative values round the
+// corners within the original radius and sides.
 func (r *Renderer) DrawPie(target *ebiten.Image, cx, cy, radius float32, startRads, endRads float64, rounding float32) {
 	if startRads == endRads || radius < 0 {
 		return // empty
@@ -267,27 +384,30 @@ func (r *Renderer) DrawPieRate(target *ebiten.Image, cx, cy, radius float32, cen
 	}
 
 	ratePi := rate * math.Pi
-	startRads, endRads := uradsAddCW(centerDir, ratePi), uradsAddCW(centerDir, ratePi)
+	centerDir = normURads(centerDir)
+	startRads, endRads := uradsAddCW(centerDir, -ratePi), uradsAddCW(centerDir, ratePi)
 	r.internalDrawPieRate(target, cx, cy, radius, centerDir, startRads, endRads, rate, rounding)
 }
 
 // preconditions: 0 < rate < 1.0, centerDir, startRads, endRads and rate are consistent
 func (r *Renderer) internalDrawPieRate(target *ebiten.Image, cx, cy, radius float32, centerDir, startRads, endRads, rate float64, rounding float32) {
+	pieRadius, apexShift, dilation, margin := r.pieRoundingParams(radius, rate, rounding)
 	pieMinX, pieMinY, pieMaxX, pieMaxY := pieBounds(cx, cy, radius, startRads, endRads)
-	pieMinX -= rounding
-	pieMinY -= rounding
-	pieMaxX += rounding
-	pieMaxY += rounding
+	pieMinX -= margin
+	pieMinY -= margin
+	pieMaxX += margin
+	pieMaxY += margin
 
-	dstOX, dstOY := rectOriginF32(target.Bounds())
-	r.setDstRectCoords(dstOX+pieMinX, dstOY+pieMinY, dstOX+pieMaxX, dstOY+pieMaxY)
+	r.setLocalRectCoords(target, pieMinX, pieMinY, pieMaxX, pieMaxY)
 
 	ensureShaderPieLoaded()
 	ws, wc := math.Sincos(rate * math.Pi)
 	r.opts.Uniforms["WedgeNormal"] = [2]float32{float32(ws), float32(wc)}
-	r.opts.Uniforms["Rounding"] = rounding
-	r.setFlatCustomVAs(cx, cy, float32(normURads(centerDir)), radius)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderPie, &r.opts)
+	r.opts.Uniforms["Rounding"] = dilation
+	r.opts.Uniforms["ApexShift"] = apexShift
+	r.setFlatCustomVAs(cx, cy, float32(normURads(centerDir)), pieRadius)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderPie)
 	clear(r.opts.Uniforms)
 }
 
@@ -317,48 +437,115 @@ func (r *Renderer) StrokePieRate(target *ebiten.Image, cx, cy, radius, thickness
 	}
 
 	ratePi := rate * math.Pi
-	startRads, endRads := uradsAddCW(centerDir, ratePi), uradsAddCW(centerDir, ratePi)
+	centerDir = normURads(centerDir)
+	startRads, endRads := uradsAddCW(centerDir, -ratePi), uradsAddCW(centerDir, ratePi)
 	r.internalStrokePieRate(target, cx, cy, radius, thickness, centerDir, startRads, endRads, rate, rounding)
 }
 
 // preconditions: 0 < rate < 1.0, centerDir, startRads, endRads and rate are consistent
 func (r *Renderer) internalStrokePieRate(target *ebiten.Image, cx, cy, radius, thickness float32, centerDir, startRads, endRads, rate float64, rounding float32) {
+	pieRadius, apexShift, dilation, margin := r.pieRoundingParams(radius, rate, rounding)
 	pieMinX, pieMinY, pieMaxX, pieMaxY := pieBounds(cx, cy, radius, startRads, endRads)
-	pieMinX -= (rounding + thickness)
-	pieMinY -= (rounding + thickness)
-	pieMaxX += (rounding + thickness)
-	pieMaxY += (rounding + thickness)
+	pieMinX -= (margin + thickness)
+	pieMinY -= (margin + thickness)
+	pieMaxX += (margin + thickness)
+	pieMaxY += (margin + thickness)
 
-	dstOX, dstOY := rectOriginF32(target.Bounds())
-	r.setDstRectCoords(dstOX+pieMinX, dstOY+pieMinY, dstOX+pieMaxX, dstOY+pieMaxY)
+	r.setLocalRectCoords(target, pieMinX, pieMinY, pieMaxX, pieMaxY)
 
 	ensureShaderStrokePieLoaded()
 	ws, wc := math.Sincos(rate * math.Pi)
 	r.opts.Uniforms["WedgeNormal"] = [2]float32{float32(ws), float32(wc)}
-	r.opts.Uniforms["Rounding"] = rounding
+	r.opts.Uniforms["Rounding"] = dilation
+	r.opts.Uniforms["ApexShift"] = apexShift
 	r.opts.Uniforms["Thickness"] = thickness
-	r.setFlatCustomVAs(cx, cy, float32(normURads(centerDir)), radius)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderStrokePie, &r.opts)
+	r.setFlatCustomVAs(cx, cy, float32(normURads(centerDir)), pieRadius)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderStrokePie)
 	clear(r.opts.Uniforms)
 }
 
+// pieRoundingParams converts a rounding value into the shader parameters for pies:
+// the radius of the base pie, its apex shift (for inner rounding), the dilation applied
+// to the base pie's SDF and the bounds margin.
+func (r *Renderer) pieRoundingParams(radius float32, rate float64, rounding float32) (pieRadius, apexShift, dilation, margin float32) {
+	if r.legacyRounding {
+		return radius - rounding, 0, rounding, rounding
+	}
+	return pieRoundingParams(radius, rate, rounding)
+}
+
+// pieRoundingParams is the renderer independent version of [Renderer.pieRoundingParams](),
+// always following the current rounding conventions.
+func pieRoundingParams(radius float32, rate float64, rounding float32) (pieRadius, apexShift, dilation, margin float32) {
+	inRounding, outRounding := splitRounding(rounding)
+	if outRounding > 0 {
+		return radius, 0, outRounding, outRounding
+	}
+
+	// inner rounding erodes the pie, which shifts the wedge apex forward,
+	// and then expands it back with rounded corners. the apex must remain
+	// within the eroded circle
+	sinHalf := float32(math.Sin(rate * math.Pi))
+	if sinHalf < 1e-4 {
+		return radius, 0, 0, 0
+	}
+	inRounding = min(inRounding, radius*sinHalf/(1.0+sinHalf)*0.999)
+	return radius - inRounding, inRounding / sinHalf, inRounding, 0
+}
+
+// ringSectorRoundingParams converts a rounding value into the shader parameters for
+// ring sectors: the radiuses of the base sector, its apex shift (for inner rounding) and
+// the dilation applied to the base sector's SDF. halfRads is half the sector aperture.
+func ringSectorRoundingParams(inRadius, outRadius float32, halfRads float64, rounding float32) (sectorInRadius, sectorOutRadius, apexShift, dilation float32) {
+	inRounding, outRounding := splitRounding(rounding)
+	if outRounding > 0 {
+		return inRadius, outRadius, 0, outRounding
+	}
+
+	// inner rounding erodes the sector, which shrinks the ring and shifts the
+	// wedge apex forward, and then expands it back with rounded corners. the
+	// apex must remain within the eroded outer circle, and for apertures over
+	// 180 degrees also within the eroded inner circle, where the shifted wedge
+	// matches the erosion
+	sinHalf := float32(math.Sin(halfRads))
+	if inRounding == 0 || sinHalf < 1e-4 {
+		return inRadius, outRadius, 0, 0
+	}
+	maxRounding := min((outRadius-inRadius)/2.0, outRadius*sinHalf/(1.0+sinHalf))
+	if math.Cos(halfRads) < 0 {
+		maxRounding = min(maxRounding, inRadius*sinHalf/(1.0-sinHalf))
+	}
+	inRounding = min(inRounding, maxRounding*0.999)
+	return inRadius + inRounding, outRadius - inRounding, inRounding / sinHalf, inRounding
+}
+
 // Notice: ellipses don't have a perfect SDF, so approximations can be very slightly
 // bigger or smaller than the requested radiuses.
 func (r *Renderer) DrawEllipse(target *ebiten.Image, cx, cy, horzRadius, vertRadius float32, rads float64) {
-	if rads == 0 {
-		r.setDstRectCoords(cx-horzRadius, cy-vertRadius, cx+horzRadius, cy+vertRadius)
-		r.opts.Uniforms["Radians"] = 0
-	} else {
-		hRadiusF64, vRadiusF64 := float64(horzRadius), float64(vertRadius)
-		rs, rc := math.Sincos(rads)
-		halfWidth := float32(math.Hypot(hRadiusF64*rc, vRadiusF64*rs))
-		halfHeight := float32(math.Hypot(hRadiusF64*rs, vRadiusF64*rc))
-		r.setDstRectCoords(cx-halfWidth, cy-halfHeight, cx+halfWidth, cy+halfHeight)
-		r.opts.Uniforms["Radians"] = rads
-	}
+	cx, cy = r.snapCenter(cx, cy)
+	horzRadius, vertRadius = r.snapRadius(horzRadius), r.snapRadius(vertRadius)
+	halfWidth, halfHeight := ellipseHalfExtents(horzRadius, vertRadius, rads)
+	r.setLocalRectCoords(target, cx-halfWidth, cy-halfHeight, cx+halfWidth, cy+halfHeight)
+	r.opts.Uniforms["Radians"] = rads
 	r.setFlatCustomVAs(cx, cy, horzRadius, vertRadius)
 	ensureShaderEllipseLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderEllipse, &r.opts)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderEllipse)
+	clear(r.opts.Uniforms)
+}
+
+// ellipseHalfExtents returns the half width and height of the axis
+// aligned box containing the ellipse rotated by the given radians.
+func ellipseHalfExtents(horzRadius, vertRadius float32, rads float64) (halfWidth, halfHeight float32) {
+	if rads == 0 {
+		return horzRadius, vertRadius
+	}
+	hRadiusF64, vRadiusF64 := float64(horzRadius), float64(vertRadius)
+	rs, rc := math.Sincos(rads)
+	halfWidth = float32(math.Hypot(hRadiusF64*rc, vRadiusF64*rs))
+	halfHeight = float32(math.Hypot(hRadiusF64*rs, vRadiusF64*rc))
+	return halfWidth, halfHeight
 }
 
 // DrawIntRect is the image.Rectangle compatible equivalent of [Renderer.DrawIntArea]().
@@ -379,7 +566,7 @@ func (r *Renderer) DrawIntArea(target *ebiten.Image, ox, oy, w, h int) {
 	minX, minY := bounds.Min.X, bounds.Min.Y
 	r.setDstRectCoords(float32(minX+ox), float32(minY+oy), float32(minX+ox+w), float32(minY+oy+h))
 	ensureShaderDefaultLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderDefault, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderDefault)
 }
 
 // StrokeIntRect is the image.Rectangle compatible equivalent of [Renderer.StrokeIntArea]().
@@ -488,16 +675,18 @@ func (r *Renderer) strokeIntInnerArea(target *ebiten.Image, ox, oy, w, h, thickn
 	}
 
 	ensureShaderDefaultLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.strokeIndices[:], shaderDefault, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.strokeIndices[:], shaderDefault)
 	r.vertices = r.vertices[:4]
 }
 
-// StrokeRect is the image.Rectangle compatible equivalent of [Renderer.DrawArea]().
-// When no rounding is required, prefer [Renderer.DrawIntArea]() instead.
+// StrokeRect is the image.Rectangle compatible equivalent of [Renderer.StrokeArea]().
+// When no rounding is required, prefer [Renderer.StrokeIntArea]() instead.
 func (r *Renderer) StrokeRect(target *ebiten.Image, rect image.Rectangle, outThickness, inThickness, rounding float32) {
 	r.StrokeArea(target, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), outThickness, inThickness, rounding)
 }
 
+// StrokeArea draws the outline of a smooth rectangle, extending outThickness outside the
+// area and inThickness inside. Rounding follows the same conventions as [Renderer.DrawArea]().
 func (r *Renderer) StrokeArea(target *ebiten.Image, ox, oy, w, h, outThickness, inThickness, rounding float32) {
 	if w < 0 {
 		w = -w
@@ -511,6 +700,12 @@ func (r *Renderer) StrokeArea(target *ebiten.Image, ox, oy, w, h, outThickness,
 	if outThickness < 0 || inThickness < 0 {
 		panic("outThickness < 0 || inThickness < 0")
 	}
+	inRounding, outRounding := r.splitRounding(rounding)
+	if outRounding > 0 {
+		ox, oy = ox-outRounding, oy-outRounding
+		w, h = w+outRounding*2, h+outRounding*2
+	}
+	rounding = min(inRounding, min(w, h)/2) + outRounding
 
 	if outThickness == 0 {
 		if inThickness != 0 {
@@ -523,16 +718,18 @@ func (r *Renderer) StrokeArea(target *ebiten.Image, ox, oy, w, h, outThickness,
 
 func (r *Renderer) strokeInnerArea(target *ebiten.Image, ox, oy, w, h, inThickness, rounding float32) {
 	ensureShaderStrokeRectLoaded()
-	r.setFlatCustomVAs(ox, oy, w, h)
+	r.setFlatCustomVAs(w, h, 0, 0)
 	r.opts.Uniforms["InnerThickness"] = inThickness
 	r.opts.Uniforms["Rounding"] = rounding
+	r.setSoftEdgeUniform()
 	r.DrawRectShader(target, ox, oy, w, h, 0, 0, shaderStrokeRect)
 	clear(r.opts.Uniforms)
 }
 
 // DrawTriangle draws a smooth triangle using the given vertices and an optional rounding factor.
-// Notice that, if provided, handling the rounding is relatively non-trivial (two dozen f64 products
-// and 3 square roots for CPU-side precomputations).
+// Positive rounding values expand the triangle with rounded corners, while negative values round
+// the corners within the original triangle. Notice that inner rounding is relatively non-trivial
+// (two dozen f64 products and 3 square roots for CPU-side precomputations).
 func (r *Renderer) DrawTriangle(target *ebiten.Image, ox1, oy1, ox2, oy2, ox3, oy3, rounding float64) {
 	r.drawTriangle(target, ox1, oy1, ox2, oy2, ox3, oy3, 0.0, rounding)
 }
@@ -547,82 +744,202 @@ func (r *Renderer) StrokeTriangle(target *ebiten.Image, ox1, oy1, ox2, oy2, ox3,
 }
 
 func (r *Renderer) drawTriangle(target *ebiten.Image, ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding float64) {
-	area := math.Abs((ox1*(oy2-oy3) + ox2*(oy3-oy1) + ox3*(oy1-oy2)) / 2)
-	if area < 1e-6 {
+	ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding = r.snapTriangle(ox1, oy1, ox2, oy2, ox3, oy3, thickness, rounding)
+	inRounding, outRounding := r.splitRounding(float32(rounding))
+	shape, shapeRounding, ok := triangleShape(ox1, oy1, ox2, oy2, ox3, oy3, float64(inRounding), float64(outRounding))
+	if !ok {
 		return // empty triangle
 	}
 
-	var iox1, ioy1, iox2, ioy2, iox3, ioy3 float64 = ox1, oy1, ox2, oy2, ox3, oy3
-	if rounding != 0 {
-		a12, b12, c12 := toLinearFormABC(ox1, oy1, ox2, oy2)
-		a23, b23, c23 := toLinearFormABC(ox2, oy2, ox3, oy3)
-		a31, b31, c31 := toLinearFormABC(ox3, oy3, ox1, oy1)
-		c1_12, c2_12 := parallelsAtDist(a12, b12, c12, rounding)
-		c1_23, c2_23 := parallelsAtDist(a23, b23, c23, rounding)
-		c1_31, c2_31 := parallelsAtDist(a31, b31, c31, rounding)
-		if a12*ox3+b12*oy3+c12 > 0 { // fancy winding order test
-			c12, c23, c31 = c1_12, c1_23, c1_31
-		} else {
-			c12, c23, c31 = c2_12, c2_23, c2_31
-		}
-		iox1, ioy1 = shortCramer(a31, b31, c31, a12, b12, c12)
-		iox2, ioy2 = shortCramer(a12, b12, c12, a23, b23, c23)
-		iox3, ioy3 = shortCramer(a23, b23, c23, a31, b31, c31)
-	}
-
+	// vertex colors are interpolated as if drawing the triangle's bounding rect
 	minX, maxX := min(ox1, ox2, ox3), max(ox1, ox2, ox3)
 	minY, maxY := min(oy1, oy2, oy3), max(oy1, oy2, oy3)
-	hthick := max(thickness/2.0, 0)
-	r.setDstRectCoords(float32(minX-hthick), float32(minY-hthick), float32(maxX+hthick), float32(maxY+hthick))
+	margin := max(thickness/2.0, 0) + float64(outRounding)
+	colorAt := r.quadColorAt(rectCorners(float32(minX-margin), float32(minY-margin), float32(maxX+margin), float32(maxY+margin)))
+	extent := shapeRounding + max(float32(thickness)/2.0, 0)
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	vertices, indices := r.polygonMesh(dstOX, dstOY, shape[:], shapeRounding, extent, colorAt)
+	for i := range vertices {
+		vertices[i].Custom3 = float32(thickness)
+	}
 
 	// draw shader
 	ensureShaderTriangleLoaded()
-	r.opts.Uniforms["P0"] = []float32{float32(iox1), float32(ioy1)}
-	r.opts.Uniforms["P1"] = []float32{float32(iox2), float32(ioy2)}
-	r.opts.Uniforms["P2"] = []float32{float32(iox3), float32(ioy3)}
-	r.opts.Uniforms["Rounding"] = float32(rounding)
-	r.opts.Uniforms["Thickness"] = float32(thickness)
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderTriangle, &r.opts)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, vertices, indices, shaderTriangle)
+	clear(r.opts.Uniforms)
+}
+
+// DrawTriangleColors is like [Renderer.DrawTriangle](), but each vertex of the triangle
+// gets its own color, with colors being interpolated barycentrically within the triangle.
+// The renderer's colors are ignored.
+func (r *Renderer) DrawTriangleColors(target *ebiten.Image, ox1, oy1, ox2, oy2, ox3, oy3, rounding float64, clr1, clr2, clr3 color.Color) {
+	inRounding, outRounding := r.splitRounding(float32(rounding))
+	shape, shapeRounding, ok := triangleShape(ox1, oy1, ox2, oy2, ox3, oy3, float64(inRounding), float64(outRounding))
+	if !ok {
+		return // empty triangle
+	}
+
+	// colors are extrapolated outside the triangle for outer rounding
+	p0 := PointF32{X: float32(ox1), Y: float32(oy1)}
+	p1 := PointF32{X: float32(ox2), Y: float32(oy2)}
+	p2 := PointF32{X: float32(ox3), Y: float32(oy3)}
+	colorAt := triangleColorAt(p0, p1, p2, ColorToF32(clr1), ColorToF32(clr2), ColorToF32(clr3))
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	vertices, indices := r.polygonMesh(dstOX, dstOY, shape[:], shapeRounding, shapeRounding, colorAt)
+
+	// draw shader
+	ensureShaderTriangleLoaded()
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, vertices, indices, shaderTriangle)
+	clear(r.opts.Uniforms)
+}
+
+// triangleShape precomputes the inset triangle and the rounding that the triangle
+// shader needs to draw the given triangle. Returns false if the triangle is empty.
+func triangleShape(ox1, oy1, ox2, oy2, ox3, oy3, inRounding, outRounding float64) ([3]PointF32, float32, bool) {
+	area := math.Abs((ox1*(oy2-oy3) + ox2*(oy3-oy1) + ox3*(oy1-oy2)) / 2)
+	if area < 1e-6 {
+		return [3]PointF32{}, 0, false
+	}
+
+	var iox1, ioy1, iox2, ioy2, iox3, ioy3 float64 = ox1, oy1, ox2, oy2, ox3, oy3
+	if inRounding != 0 {
+		perimeter := math.Hypot(ox2-ox1, oy2-oy1) + math.Hypot(ox3-ox2, oy3-oy2) + math.Hypot(ox1-ox3, oy1-oy3)
+		inRounding = min(inRounding, 2*area/perimeter) // inradius
+		iox1, ioy1, iox2, ioy2, iox3, ioy3 = insetTriangle(ox1, oy1, ox2, oy2, ox3, oy3, inRounding)
+	}
+
+	shape := [3]PointF32{
+		{X: float32(iox1), Y: float32(ioy1)},
+		{X: float32(iox2), Y: float32(ioy2)},
+		{X: float32(iox3), Y: float32(ioy3)},
+	}
+	return shape, float32(inRounding + outRounding), true
 }
 
 // DrawHexagon renders an hexagon that can be fully contained within the given radius.
-// Roundness can be used to round the corners. Rads can be used to rotate the hexagon,
+// Rounding can be used to round the corners, with positive values expanding the hexagon
+// and negative values preserving its radius. Rads can be used to rotate the hexagon,
 // in radians.
-func (r *Renderer) DrawHexagon(target *ebiten.Image, ox, oy, radius, roundness, rads float32) {
-	r.setDstRectCoords(ox-radius, oy-radius, ox+radius, oy+radius)
+func (r *Renderer) DrawHexagon(target *ebiten.Image, ox, oy, radius, rounding, rads float32) {
+	inRounding, outRounding := r.splitRounding(rounding)
+	bounds := radius + outRounding
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	r.setDstRectCoords(dstOX+ox-bounds, dstOY+oy-bounds, dstOX+ox+bounds, dstOY+oy+bounds)
+	r.setSrcRectCoords(-bounds, -bounds, bounds, bounds)
 
 	// draw shader
 	const apothemToRadiusFactor = 0.866025404 // math.Sqrt(3)/2
-	apothem := (radius - roundness) * apothemToRadiusFactor
-	r.setFlatCustomVAs(ox, oy, apothem, rads)
-	r.opts.Uniforms["Roundness"] = roundness
+	apothem := (radius - inRounding) * apothemToRadiusFactor
+	r.setFlatCustomVAs(apothem, rads, inRounding+outRounding, 0)
 	ensureShaderHexagonLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderHexagon, &r.opts)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderHexagon)
+	clear(r.opts.Uniforms)
 }
 
 // DrawQuad renders a convex quad with the current renderer colors.
-// The thickening acts as a rounding parameter, but it extends the shape outwards
-// instead of "cutting" the corners. Notice that non-zero thickening involves
-// additional CPU-side precomputations.
+// Positive rounding values expand the quad with rounded corners, while
+// negative values round the corners within the original quad. Notice that
+// non-zero rounding involves additional CPU-side precomputations.
 //
 // quad must be given in clockwise order starting from top-left.
-func (r *Renderer) DrawQuad(target *ebiten.Image, quad [4]PointF32, thickening float32) {
-	r.DrawQuadSoft(target, quad, thickening, 1.3333)
+func (r *Renderer) DrawQuad(target *ebiten.Image, quad [4]PointF32, rounding float32) {
+	if r.pixelPerfect {
+		r.DrawQuadSoft(target, quad, rounding, 0)
+	} else {
+		r.DrawQuadSoft(target, quad, rounding, r.softEdge)
+	}
 }
 
-func (r *Renderer) DrawQuadSoft(target *ebiten.Image, quad [4]PointF32, thickening, softEdge float32) {
-	minX, minY, _, _ := rectOriginSizeF32(target.Bounds())
-	for i, pt := range expandQuad(quad, thickening) {
-		r.vertices[i].DstX = minX + pt.X
-		r.vertices[i].DstY = minY + pt.Y
+// DrawQuadSoft is like [Renderer.DrawQuad](), but with an explicit softEdge
+// instead of the renderer's [Renderer.SetSoftEdge]() value.
+func (r *Renderer) DrawQuadSoft(target *ebiten.Image, quad [4]PointF32, rounding, softEdge float32) {
+	// quads always followed the expansion convention, so
+	// legacy mode doesn't apply here
+	shape := quad
+	if rounding < 0 {
+		shape = expandQuad(quad, rounding)
+		rounding = -rounding
 	}
 
-	r.setFlatCustomVAs01(thickening, softEdge)
+	// vertex colors are interpolated as if drawing the expanded quad
+	colorAt := r.quadColorAt(expandQuad(shape, rounding))
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	vertices, indices := r.polygonMesh(dstOX, dstOY, shape[:], rounding, rounding, colorAt)
 	ensureShaderQuadLoaded()
-	r.opts.Uniforms["Quad"] = [8]float32{
-		quad[0].X, quad[0].Y, quad[1].X, quad[1].Y,
-		quad[2].X, quad[2].Y, quad[3].X, quad[3].Y,
+	r.opts.Uniforms["SoftEdge"] = max(r.localSoftEdge(softEdge), 0.001)
+	r.drawTrianglesShader(target, vertices, indices, shaderQuad)
+	clear(r.opts.Uniforms)
+}
+
+// MaxMetaballs is the maximum number of circles accepted by [Renderer.DrawMetaballs]().
+const MaxMetaballs = 64
+
+// DrawMetaballs renders the iso-surface of a summed field of circles in a single pass,
+// with the renderer's color. Each circle contributes radius^2/dist^2 to the field, and
+// pixels with a field value above the threshold are filled. With threshold = 1, a lone
+// circle is drawn with its exact radius, while close circles merge smoothly. Lower
+// thresholds make the blobs grow and merge earlier.
+//
+// softEdge controls the antialiasing width, in pixels. [AAMargin] is a reasonable default,
+// while higher values can be used for blurry liquid effects.
+//
+// The function panics if len(centers) != len(radii), more than [MaxMetaballs] circles are
+// given, threshold <= 0 or softEdge < 0.
+func (r *Renderer) DrawMetaballs(target *ebiten.Image, centers []PointF32, radii []float32, threshold, softEdge float32) {
+	if len(centers) != len(radii) {
+		panic("len(centers) != len(radii)")
 	}
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderQuad, &r.opts)
+	if len(centers) > MaxMetaballs {
+		panic("len(centers) > MaxMetaballs")
+	}
+	if threshold <= 0 {
+		panic("threshold <= 0")
+	}
+	if softEdge < 0 {
+		panic("softEdge < 0")
+	}
+	if len(centers) == 0 {
+		return // nothing to draw
+	}
+
+	minX, minY, maxX, maxY, ok := metaballsRect(centers, radii, threshold)
+	if !ok {
+		return // nothing to draw
+	}
+	var balls [MaxMetaballs * 3]float32
+	for i, center := range centers {
+		balls[i*3+0], balls[i*3+1], balls[i*3+2] = center.X, center.Y, radii[i]
+	}
+	r.setLocalRectCoords(target, minX, minY, maxX, maxY)
+
+	// draw shader
+	r.setFlatCustomVAs(threshold, max(r.localSoftEdge(softEdge), 0.001), float32(len(centers)), 0)
+	r.opts.Uniforms["Balls"] = balls
+	ensureShaderMetaballsLoaded()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderMetaballs)
 	clear(r.opts.Uniforms)
 }
+
+// metaballsRect returns the area drawn by [Renderer.DrawMetaballs](), or false
+// if all radii are zero. f(p) >= threshold implies that p is at distance
+// <= sqrt(sum(r^2)/threshold) of at least one of the centers.
+func metaballsRect(centers []PointF32, radii []float32, threshold float32) (minX, minY, maxX, maxY float32, ok bool) {
+	var sumRadiiSq float32
+	for _, radius := range radii {
+		sumRadiiSq += radius * radius
+	}
+	if sumRadiiSq == 0 {
+		return 0, 0, 0, 0, false
+	}
+	reach := float32(math.Sqrt(float64(sumRadiiSq / threshold)))
+	minX, minY = centers[0].X, centers[0].Y
+	maxX, maxY = minX, minY
+	for _, center := range centers[1:] {
+		minX, minY = min(minX, center.X), min(minY, center.Y)
+		maxX, maxY = max(maxX, center.X), max(maxY, center.Y)
+	}
+	return minX - reach, minY - reach, maxX + reach, maxY + reach, true
+}
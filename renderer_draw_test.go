@@ -24,9 +24,9 @@ func TestDrawShapes(t *testing.T) {
 		ctx.Renderer.SetColor(color.RGBA{240, 48, 48, 255})
 		ctx.Renderer.DrawTriangle(canvas, x, y, x+70, y-20, x+114, y+80, 0)
 		ctx.Renderer.SetColor(color.RGBA{255, 255, 255, 255})
-		ctx.Renderer.DrawTriangle(canvas, x, y, x+70, y-20, x+114, y+80, 8)
+		ctx.Renderer.DrawTriangle(canvas, x, y, x+70, y-20, x+114, y+80, -8)
 		x, y = float64(200), float64(300)
-		ctx.Renderer.StrokeTriangle(canvas, x+70, y-20, x, y, x+114, y+80, 4, 8)
+		ctx.Renderer.StrokeTriangle(canvas, x+70, y-20, x, y, x+114, y+80, 4, -8)
 		v := uint8(32 + ctx.DistAnim(196-32, 1.0))
 		ctx.Renderer.SetColor(color.RGBA{v, 0, 0, v})
 		ctx.Renderer.StrokeTriangle(canvas, x+70, y-20, x, y, x+114, y+80, -4, 0)
@@ -40,6 +40,38 @@ func TestDrawShapes(t *testing.T) {
 	}
 }
 
+// go test -run ^TestDrawTaperedLine$ . -count 1
+func TestDrawTaperedLine(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		lx, ly := ctx.LeftClickF64()
+		rx, ry := ctx.RightClickF64()
+		thick := 2.0 + ctx.DistAnim(46.0, 1.0)
+		ctx.Renderer.DrawTaperedLine(canvas, lx, ly, rx, ry, thick, 2.0, color.RGBA{255, 196, 0, 255}, color.RGBA{128, 0, 0, 0})
+		ctx.Renderer.DrawTaperedLine(canvas, 64, 64, 256, 96, 0.0, 24.0, color.RGBA{0, 0, 0, 0}, color.RGBA{0, 196, 255, 255})
+		ctx.Renderer.DrawTaperedLine(canvas, 96, 400, 112, 400, 64.0, 8.0, color.RGBA{255, 255, 255, 255}, color.RGBA{0, 255, 0, 255})
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestDrawTriangleColors$ . -count 1
+func TestDrawTriangleColors(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		lx, ly := ctx.LeftClickF64()
+		rx, ry := ctx.RightClickF64()
+		red, green, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}
+		ctx.Renderer.DrawTriangleColors(canvas, lx, ly, rx, ry, lx, ry, 0, red, green, blue)
+		rounding := ctx.DistAnim(24.0, 1.0)
+		ctx.Renderer.DrawTriangleColors(canvas, 64, 64, 256, 96, 128, 240, rounding, red, green, color.RGBA{0, 0, 0, 0})
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // go test -run ^TestDrawArea$ . -count 1
 func TestDrawArea(t *testing.T) {
 	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
@@ -49,7 +81,7 @@ func TestDrawArea(t *testing.T) {
 		ctx.Renderer.SetColorF32(1.0, 1.0, 1.0, 1.0)
 		w1, h1 := float32(128), float32(48)
 		w2, h2 := float32(48), float32(128)
-		ctx.Renderer.DrawArea(canvas, lx-w1/2, ly-h1/2, w1, h1, float32(ctx.DistAnim(float64(min(w1, h1))/2.0, 1.0)))
+		ctx.Renderer.DrawArea(canvas, lx-w1/2, ly-h1/2, w1, h1, -float32(ctx.DistAnim(float64(min(w1, h1))/2.0, 1.0)))
 		ctx.Renderer.DrawArea(canvas, rx-w2/2, ry-h2/2, w2, h2, 0)
 
 		ctx.Renderer.SetColorF32(0.2, 0.0, 0.2, 0.2)
@@ -61,6 +93,109 @@ func TestDrawArea(t *testing.T) {
 	}
 }
 
+// go test -run ^TestRoundingConventions$ . -count 1
+func TestRoundingConventions(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		ctx.Renderer.SetLegacyRounding(ebiten.IsKeyPressed(ebiten.KeySpace))
+		rounding := float32(ctx.DistAnim(16.0, 1.0))
+
+		// each column draws the shapes with negative, zero and positive
+		// rounding over the reference shapes without rounding
+		for col, sign := range []float32{-1, 0, +1} {
+			x := float32(96 + col*160)
+			for pass := range 2 {
+				r := rounding * sign
+				if pass == 0 {
+					r = 0
+					ctx.Renderer.SetColorF32(0.3, 0.0, 0.3, 0.3)
+				} else {
+					ctx.Renderer.SetColorF32(0.8, 0.8, 0.8, 0.8)
+				}
+				ctx.Renderer.DrawArea(canvas, x-48, 32, 96, 64, r)
+				ctx.Renderer.DrawTriangle(canvas, float64(x-48), 176, float64(x+48), 136, float64(x), 216, float64(r))
+				ctx.Renderer.DrawHexagon(canvas, x, 296, 48, r, 0)
+				ctx.Renderer.DrawPie(canvas, x-24, 408, 64, RadsRight-0.6, RadsRight+0.6, r)
+				ctx.Renderer.DrawRingSector(canvas, x-24, 528, 32, 64, RadsRight-0.6, RadsRight+0.6, r)
+				quad := [4]PointF32{{x - 48, 608}, {x + 40, 600}, {x + 48, 664}, {x - 40, 672}}
+				ctx.Renderer.DrawQuad(canvas, quad, r)
+			}
+		}
+		ctx.Renderer.SetLegacyRounding(false)
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestSetSoftEdge$ . -count 1
+func TestSetSoftEdge(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		softEdge := float32(ctx.DistAnim(32.0, 0.5))
+		if ebiten.IsKeyPressed(ebiten.KeySpace) {
+			softEdge = 0
+		}
+		ctx.Renderer.SetSoftEdge(softEdge)
+		ctx.Renderer.DrawCircle(canvas, 96, 96, 64)
+		ctx.Renderer.StrokeCircle(canvas, 256, 96, 56, 16)
+		ctx.Renderer.DrawRing(canvas, 416, 96, 32, 64)
+		ctx.Renderer.DrawArea(canvas, 32, 192, 128, 96, -16)
+		ctx.Renderer.StrokeArea(canvas, 192, 192, 128, 96, 8, 8, 12)
+		ctx.Renderer.DrawPie(canvas, 416, 240, 64, RadsRight-1.0, RadsRight+1.0, 0)
+		ctx.Renderer.DrawTriangle(canvas, 32, 432, 160, 432, 96, 320, -8)
+		ctx.Renderer.DrawLine(canvas, 192, 432, 320, 336, 16)
+		ctx.Renderer.DrawEllipse(canvas, 416, 384, 64, 32, 0.3)
+		ctx.Renderer.SetSoftEdge(AAMargin)
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestPixelPerfect$ . -count 1
+func TestPixelPerfect(t *testing.T) {
+	const size = 16
+	centers := [][2]float32{{8, 8}, {8.2, 8.7}, {8.99, 8.01}}
+
+	// midpoint circle of radius 3, centered at pixel (8, 8)
+	expected := []string{
+		"..###..",
+		".#####.",
+		"#######",
+		"#######",
+		"#######",
+		".#####.",
+		"..###..",
+	}
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		r.SetPixelPerfect(true)
+		img := ebiten.NewImage(size, size)
+		pix := make([]byte, size*size*4)
+		for _, center := range centers {
+			img.Clear()
+			r.DrawCircle(img, center[0], center[1], 3)
+			img.ReadPixels(pix)
+		pixels:
+			for y := range size {
+				for x := range size {
+					alpha := pix[(y*size+x)*4+3]
+					want := byte(0)
+					ex, ey := x-5, y-5
+					if ex >= 0 && ex < 7 && ey >= 0 && ey < 7 && expected[ey][ex] == '#' {
+						want = 255
+					}
+					if alpha != want {
+						fail("center %v: expected alpha %d at (%d, %d), got %d", center, want, x, y, alpha)
+						break pixels
+					}
+				}
+			}
+		}
+	})
+}
+
 // go test -run ^TestDrawIntArea$ . -count 1
 func TestStrokeIntArea(t *testing.T) {
 	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
@@ -105,7 +240,7 @@ func TestStrokeArea(t *testing.T) {
 
 		rx, ry := ctx.RightClickF32()
 		ctx.Renderer.SetColor(color.RGBA{240, 0, 240, 255}, 0, 2)
-		ctx.Renderer.StrokeArea(canvas, rx, ry, 100, 50, 4, 4, 25)
+		ctx.Renderer.StrokeArea(canvas, rx, ry, 100, 50, 4, 4, -25)
 
 		a := uint8(ctx.DistAnim(144.0, 1.0))
 		ctx.Renderer.SetColor(color.RGBA{a, a, a, a})
@@ -117,13 +252,48 @@ func TestStrokeArea(t *testing.T) {
 		ctx.Renderer.SetColor(color.RGBA{0, 255, 255, 255}, 3)
 		extra := float32(ctx.DistAnim(16, 1.0))
 		subRounding := float32(ctx.DistAnim(20, 1.0))
-		ctx.Renderer.StrokeArea(canvas, lx, ry, 80+extra, 50, 8, 8, 25-subRounding)
+		ctx.Renderer.StrokeArea(canvas, lx, ry, 80+extra, 50, 8, 8, subRounding-25)
 	})
 	if err := ebiten.RunGame(app); err != nil {
 		t.Fatal(err)
 	}
 }
 
+// go test -run ^TestStrokeAreaRounding$ . -count 1
+func TestStrokeAreaRounding(t *testing.T) {
+	const w, h = 96, 72
+	tests := []struct {
+		rounding float32
+		legacy   bool
+	}{
+		{-8, false}, {0, false}, {6, false}, {6, true},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		fillImg, strokeImg := ebiten.NewImage(w, h), ebiten.NewImage(w, h)
+		fillPix, strokePix := make([]byte, w*h*4), make([]byte, w*h*4)
+		for _, test := range tests {
+			// an inner stroke thick enough to cover the whole
+			// area must match the filled area
+			r.SetLegacyRounding(test.legacy)
+			fillImg.Clear()
+			strokeImg.Clear()
+			r.DrawArea(fillImg, 16.5, 14, 60, 40, test.rounding)
+			r.StrokeArea(strokeImg, 16.5, 14, 60, 40, 0, 40, test.rounding)
+			fillImg.ReadPixels(fillPix)
+			strokeImg.ReadPixels(strokePix)
+			if msg := comparePixels(fillPix, strokePix, w, 2); msg != "" {
+				fail("rounding %v, legacy %v: %s", test.rounding, test.legacy, msg)
+			}
+			if bounds := r.StrokeAreaBounds(16.5, 14, 60, 40, 0, 40, test.rounding); bounds != r.AreaBounds(16.5, 14, 60, 40, test.rounding) {
+				fail("rounding %v, legacy %v: StrokeAreaBounds() doesn't match AreaBounds()", test.rounding, test.legacy)
+			}
+		}
+		r.SetLegacyRounding(false)
+	})
+}
+
 // go test -run ^TestStrokeCircle$ . -count 1
 func TestStrokeCircle(t *testing.T) {
 	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
@@ -238,10 +408,10 @@ func TestDrawPie(t *testing.T) {
 		rate := -0.01 + ctx.DistAnim(1.02, 1.0)
 
 		ctx.Renderer.SetColorF32(1.0, 1.0, 1.0, 1.0)
-		ctx.Renderer.DrawPieRate(canvas, cx, cy, 96.0, RadsRight, rate, 6.0)
+		ctx.Renderer.DrawPieRate(canvas, cx, cy, 96.0, RadsRight, rate, -6.0)
 
 		ctx.Renderer.SetColorF32(0.0, 1.0, 0.0, 1.0)
-		ctx.Renderer.DrawPie(canvas, cx, cy, 64.0, RadsRight+rate, RadsBottom, 3.0)
+		ctx.Renderer.DrawPie(canvas, cx, cy, 64.0, RadsRight+rate, RadsBottom, -3.0)
 	})
 	if err := ebiten.RunGame(app); err != nil {
 		t.Fatal(err)
@@ -267,6 +437,47 @@ func TestStrokePie(t *testing.T) {
 	}
 }
 
+// go test -run ^TestPieRateMatchesPie$ . -count 1
+func TestPieRateMatchesPie(t *testing.T) {
+	const size = 96
+	tests := []struct {
+		centerDir, rate float64
+		rounding        float32
+	}{
+		{RadsTop, 0.1, 0},
+		{RadsRight, 0.3, -4},
+		{RadsBottomLeft - 2*math.Pi, 0.6, 4},
+		{RadsTopLeft + 4*math.Pi, 0.9, -6},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		rateImg, pieImg := ebiten.NewImage(size, size), ebiten.NewImage(size, size)
+		ratePix, piePix := make([]byte, size*size*4), make([]byte, size*size*4)
+		for _, test := range tests {
+			// the pie must span rate*pi radians on each side of centerDir
+			startRads := test.centerDir - test.rate*math.Pi
+			endRads := test.centerDir + test.rate*math.Pi
+			for _, stroke := range []bool{false, true} {
+				rateImg.Clear()
+				pieImg.Clear()
+				if stroke {
+					r.StrokePieRate(rateImg, size/2, size/2, 36, 3, test.centerDir, test.rate, test.rounding)
+					r.StrokePie(pieImg, size/2, size/2, 36, 3, startRads, endRads, test.rounding)
+				} else {
+					r.DrawPieRate(rateImg, size/2, size/2, 36, test.centerDir, test.rate, test.rounding)
+					r.DrawPie(pieImg, size/2, size/2, 36, startRads, endRads, test.rounding)
+				}
+				rateImg.ReadPixels(ratePix)
+				pieImg.ReadPixels(piePix)
+				if msg := comparePixels(piePix, ratePix, size, 2); msg != "" {
+					fail("centerDir %.2f, rate %v, stroke %v: %s", test.centerDir, test.rate, stroke, msg)
+				}
+			}
+		}
+	})
+}
+
 // go test -run ^TestDrawQuad$ . -count 1
 func TestDrawQuad(t *testing.T) {
 	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
@@ -308,3 +519,29 @@ func TestDrawQuadSoft(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// go test -run ^TestDrawMetaballs$ . -count 1
+func TestDrawMetaballs(t *testing.T) {
+	centers := make([]PointF32, 5)
+	radii := []float32{48, 32, 32, 24, 40}
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		lx, ly := ctx.LeftClickF32()
+		rx, ry := ctx.RightClickF32()
+		centers[0] = PointF32{X: lx, Y: ly}
+		centers[1] = PointF32{X: rx, Y: ry}
+		rads := ctx.RadsAnim(0.5)
+		for i := 2; i < len(centers); i++ {
+			s, c := math.Sincos(rads * float64(i))
+			centers[i] = PointF32{X: (lx+rx)/2 + float32(96*c), Y: (ly+ry)/2 + float32(96*s)}
+		}
+		ctx.Renderer.SetColor(color.RGBA{0, 196, 255, 255})
+		ctx.Renderer.DrawMetaballs(canvas, centers, radii, 1.0, AAMargin)
+		ctx.Renderer.SetColor(color.RGBA{255, 255, 255, 64})
+		ctx.Renderer.DrawMetaballs(canvas, centers, radii, 0.5, 16.0)
+		ctx.Renderer.SetColor(color.RGBA{255, 255, 255, 255})
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
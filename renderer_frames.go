@@ -0,0 +1,70 @@
+package shapes
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// FrameEffect is an effect applied by [Renderer.ApplyToFrames]() to a single frame.
+// Like the Apply* functions, it must draw 'frame' into 'target' at (ox, oy). For example:
+//
+//	outline := func(r *shapes.Renderer, target, frame *ebiten.Image, ox, oy float32) {
+//		r.ApplyOutline(target, frame, ox, oy, 2)
+//		r.Scale(target, frame, ox, oy, 1.0, false) // draw the frame over its outline
+//	}
+type FrameEffect func(r *Renderer, target, frame *ebiten.Image, ox, oy float32)
+
+// FramesSheetSize returns the size of the sheet that [Renderer.ApplyToFrames]() requires
+// as a target for the given source sheet size, frame size and padding.
+func FramesSheetSize(sheetWidth, sheetHeight, frameWidth, frameHeight, padding int) (int, int) {
+	cols, rows := framesGrid(sheetWidth, sheetHeight, frameWidth, frameHeight)
+	return cols * (frameWidth + padding*2), rows * (frameHeight + padding*2)
+}
+
+// ApplyToFrames applies the given effect to every frame of a sprite sheet, writing the
+// results to 'target' as a new sheet where each frame is surrounded by 'padding' pixels
+// on all sides. The padding should be big enough for the effect margins, which can be
+// obtained from functions like [Renderer.GlowMargins](). Frames are read in row-major
+// order, and each frame is drawn at the same row and column on the target.
+//
+// Effects don't bleed between frames: sources are never sampled outside their bounds,
+// and each frame's effect is drawn to a subimage of the target, so anything beyond the
+// padding gets clipped instead of reaching neighboring frames.
+//
+// The target must be at least as big as [FramesSheetSize](). The function panics if the
+// sheet size is not a multiple of the frame size, or if padding is negative.
+func (r *Renderer) ApplyToFrames(target, sheet *ebiten.Image, frameWidth, frameHeight, padding int, effect FrameEffect) {
+	if padding < 0 {
+		panic("padding can't be negative")
+	}
+	sheetBounds := sheet.Bounds()
+	outWidth, outHeight := FramesSheetSize(sheetBounds.Dx(), sheetBounds.Dy(), frameWidth, frameHeight, padding)
+	targetBounds := target.Bounds()
+	if targetBounds.Dx() < outWidth || targetBounds.Dy() < outHeight {
+		panic("target is too small, see FramesSheetSize()")
+	}
+
+	cols, rows := framesGrid(sheetBounds.Dx(), sheetBounds.Dy(), frameWidth, frameHeight)
+	cellWidth, cellHeight := frameWidth+padding*2, frameHeight+padding*2
+	for row := range rows {
+		for col := range cols {
+			sx, sy := sheetBounds.Min.X+col*frameWidth, sheetBounds.Min.Y+row*frameHeight
+			frame := sheet.SubImage(image.Rect(sx, sy, sx+frameWidth, sy+frameHeight)).(*ebiten.Image)
+			tx, ty := targetBounds.Min.X+col*cellWidth, targetBounds.Min.Y+row*cellHeight
+			cell := target.SubImage(image.Rect(tx, ty, tx+cellWidth, ty+cellHeight)).(*ebiten.Image)
+			effect(r, cell, frame, float32(padding), float32(padding))
+		}
+	}
+}
+
+// framesGrid returns the number of columns and rows of frames in a sheet.
+func framesGrid(sheetWidth, sheetHeight, frameWidth, frameHeight int) (cols, rows int) {
+	if frameWidth <= 0 || frameHeight <= 0 {
+		panic("frame size must be positive")
+	}
+	if sheetWidth%frameWidth != 0 || sheetHeight%frameHeight != 0 {
+		panic("sheet size must be a multiple of the frame size")
+	}
+	return sheetWidth / frameWidth, sheetHeight / frameHeight
+}
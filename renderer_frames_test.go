@@ -0,0 +1,76 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestFramesSheetSize(t *testing.T) {
+	w, h := FramesSheetSize(96, 64, 32, 32, 4)
+	if w != 120 || h != 80 {
+		t.Errorf("got %dx%d, want 120x80", w, h)
+	}
+	w, h = FramesSheetSize(32, 32, 32, 32, 0)
+	if w != 32 || h != 32 {
+		t.Errorf("no padding: got %dx%d, want 32x32", w, h)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for sheet size not multiple of frame size")
+		}
+	}()
+	FramesSheetSize(100, 64, 32, 32, 4)
+}
+
+// go test -run ^TestApplyToFrames$ . -count 1
+func TestApplyToFrames(t *testing.T) {
+	const cols, rows = 3, 2
+	const frameSize, padding = 32, 8
+
+	glow := func(r *Renderer, target, frame *ebiten.Image, ox, oy float32) {
+		r.ApplyGlow(target, frame, ox, oy, 12, 12, 0, 0.5, 1)
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+
+		// opaque frames, so any bleeding would be visible on the neighbors
+		colors := []color.RGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}}
+		sheet := ebiten.NewImage(cols*frameSize, rows*frameSize)
+		for i := range cols * rows {
+			x, y := (i%cols)*frameSize, (i/cols)*frameSize
+			r.SetColor(colors[i%len(colors)])
+			r.DrawIntArea(sheet, x, y, frameSize, frameSize)
+		}
+
+		outWidth, outHeight := FramesSheetSize(cols*frameSize, rows*frameSize, frameSize, frameSize, padding)
+		out := ebiten.NewImage(outWidth, outHeight)
+		r.SetColor(color.White)
+		r.ApplyToFrames(out, sheet, frameSize, frameSize, padding, glow)
+
+		cellSize := frameSize + padding*2
+		ref := ebiten.NewImage(cellSize, cellSize)
+		refPix := make([]byte, cellSize*cellSize*4)
+		cellPix := make([]byte, cellSize*cellSize*4)
+		for i := range cols * rows {
+			col, row := i%cols, i/cols
+			frame := ebiten.NewImage(frameSize, frameSize)
+			frame.Fill(colors[i%len(colors)])
+			ref.Clear()
+			glow(r, ref, frame, padding, padding)
+			ref.ReadPixels(refPix)
+
+			cellRect := image.Rect(col*cellSize, row*cellSize, (col+1)*cellSize, (row+1)*cellSize)
+			out.SubImage(cellRect).(*ebiten.Image).ReadPixels(cellPix)
+			if msg := comparePixels(refPix, cellPix, cellSize, 2); msg != "" {
+				fail("frame %d: %s", i, msg)
+			} else if isTransparent(refPix) {
+				fail("frame %d: reference output is fully transparent", i)
+			}
+		}
+	})
+}
@@ -84,10 +84,11 @@ func (r *Renderer) JFMCompute(jfmap, source *ebiten.Image, initMode JFMInitMode,
 	}
 	r.setFlatCustomVAs01(minAlpha, maxAlpha)
 
-	// init
+	// init (maps are data, so they are never transformed)
 	memoBlend := r.opts.Blend
 	r.opts.Blend = ebiten.BlendCopy
-	temp := r.getTemp(0, sw, sh, false)
+	r.beginInternalPass()
+	temp := r.getTemp(0, sw, sh, false, jfmap, source)
 
 	dstOX, dstOY := float32(tbounds.Min.X), float32(tbounds.Min.Y)
 	w, h := float32(sw), float32(sh)
@@ -96,14 +97,14 @@ func (r *Renderer) JFMCompute(jfmap, source *ebiten.Image, initMode JFMInitMode,
 	srcOX, srcOY := float32(sbounds.Min.X), float32(sbounds.Min.Y)
 	r.setSrcRectCoords(srcOX, srcOY, srcOX+w, srcOY+h)
 	r.opts.Images[0] = source
-	temp.DrawTrianglesShader(r.vertices[:], r.indices[:], initShader, &r.opts)
+	r.drawTrianglesShader(temp, r.vertices[:], r.indices[:], initShader)
 
 	// we use 1+JFA, so the first pass uses jump size = 1
 	r.setFlatCustomVAs01(1.0, float32(maxDistance))
 	r.opts.Images[0] = temp
 	r.setDstRectCoords(mapCoords[0][0], mapCoords[0][1], mapCoords[0][2], mapCoords[0][3])
 	r.setSrcRectCoords(mapCoords[1][0], mapCoords[1][1], mapCoords[1][2], mapCoords[1][3])
-	jfmap.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderJFMPass, &r.opts)
+	r.drawTrianglesShader(jfmap, r.vertices[:], r.indices[:], shaderJFMPass)
 
 	// - main JFA loop -
 	// jump size starts at the base power of 2 of the current number
@@ -116,7 +117,7 @@ func (r *Renderer) JFMCompute(jfmap, source *ebiten.Image, initMode JFMInitMode,
 		newIndex := 1 - mapIndex
 		r.setSrcRectCoords(mapCoords[newIndex][0], mapCoords[newIndex][1], mapCoords[newIndex][2], mapCoords[newIndex][3])
 		r.opts.Images[0] = maps[newIndex]
-		maps[mapIndex].DrawTrianglesShader(r.vertices[:], r.indices[:], shaderJFMPass, &r.opts)
+		r.drawTrianglesShader(maps[mapIndex], r.vertices[:], r.indices[:], shaderJFMPass)
 		mapIndex = newIndex
 		jumpSize /= 2
 	}
@@ -125,10 +126,11 @@ func (r *Renderer) JFMCompute(jfmap, source *ebiten.Image, initMode JFMInitMode,
 	if mapIndex == 0 {
 		var opts ebiten.DrawImageOptions
 		opts.Blend = ebiten.BlendCopy
-		jfmap.DrawImage(temp, &opts)
+		r.drawImage(jfmap, temp, &opts)
 	}
 
 	// cleanup
+	r.endInternalPass()
 	r.opts.Blend = memoBlend
 	r.opts.Images[0] = nil
 }
@@ -157,10 +159,10 @@ func (r *Renderer) JFMComputeUnsafeTemp(offscreenIndex int, source *ebiten.Image
 	} else {
 		ox = w
 	}
-	temp := r.getTemp(offscreenIndex, ox+w, oy+h, false)
+	temp := r.getTemp(offscreenIndex, ox+w, oy+h, false, source)
 	var opts ebiten.DrawImageOptions
 	opts.Blend = ebiten.BlendCopy
-	temp.DrawImage(source, &opts)
+	r.drawImage(temp, source, &opts)
 
 	sourceTemp = temp.SubImage(image.Rect(0, 0, w, h)).(*ebiten.Image)
 	jfmapTemp = temp.SubImage(image.Rect(ox, oy, ox+w, oy+h)).(*ebiten.Image)
@@ -187,6 +189,7 @@ func (r *Renderer) JFMExpand(target, source, jfmap *ebiten.Image, ox, oy, thickn
 	}
 
 	if jfmap == nil {
+		r.pool.checkTempAliasing(1, target)
 		jfmapMaxDist := max(int(math.Ceil(float64(thickness))), 1)
 		source, jfmap = r.JFMComputeUnsafeTemp(1, source, JFMPixel, jfmapMaxDist, 0.001, 1.0)
 	}
@@ -213,6 +216,7 @@ func (r *Renderer) JFMErode(target, source, jfmap *ebiten.Image, ox, oy, radius,
 	}
 
 	if jfmap == nil {
+		r.pool.checkTempAliasing(1, target)
 		jfmapMaxDist := max(int(math.Ceil(float64(radius))), 1)
 		source, jfmap = r.JFMComputeUnsafeTemp(1, source, JFMPixel, jfmapMaxDist, 0.0, 0.0)
 	}
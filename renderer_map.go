@@ -6,9 +6,10 @@ import (
 
 // quad must be given in clockwise order starting from top-left.
 func (r *Renderer) mapQuad2(target, source *ebiten.Image, quad [4]PointF32) {
+	dstOX, dstOY := rectOriginF32(target.Bounds())
 	for i, pt := range quad {
-		r.vertices[i].DstX = pt.X
-		r.vertices[i].DstY = pt.Y
+		r.vertices[i].DstX = dstOX + pt.X
+		r.vertices[i].DstY = dstOY + pt.Y
 	}
 
 	minX, minY, srcWidth, srcHeight := rectOriginSizeF32(source.Bounds())
@@ -16,7 +17,7 @@ func (r *Renderer) mapQuad2(target, source *ebiten.Image, quad [4]PointF32) {
 	r.setFlatCustomVAs01(1.0, 1.0)
 	r.opts.Images[0] = source
 	ensureShaderBilinearLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderBilinear, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderBilinear)
 	r.opts.Images[0] = nil
 }
 
@@ -30,18 +31,19 @@ func (r *Renderer) mapQuad2(target, source *ebiten.Image, quad [4]PointF32) {
 // The renderer's color is applied multiplicatively as a color scale;
 // set it to white for neutral operation.
 func (r *Renderer) MapQuad4(target, source *ebiten.Image, quad [4]PointF32) {
+	dstOX, dstOY := rectOriginF32(target.Bounds())
 	for i, pt := range quad {
-		r.vertices[i].DstX = pt.X
-		r.vertices[i].DstY = pt.Y
+		r.vertices[i].DstX = dstOX + pt.X
+		r.vertices[i].DstY = dstOY + pt.Y
 	}
 	ctr := quadCenter(quad)
 	ctrVert := r.vertices[0]
-	ctrVert.DstX = ctr.X
-	ctrVert.DstY = ctr.Y
+	ctrVert.DstX = dstOX + ctr.X
+	ctrVert.DstY = dstOY + ctr.Y
 
 	minX, minY, srcWidth, srcHeight := rectOriginSizeF32(source.Bounds())
 	ctrVert.SrcX = minX + srcWidth/2.0
-	ctrVert.SrcY = minX + srcHeight/2.0
+	ctrVert.SrcY = minY + srcHeight/2.0
 	r.vertices = append(r.vertices, ctrVert)
 
 	r.setSrcRectCoords(minX, minY, minX+srcWidth, minY+srcHeight)
@@ -54,7 +56,7 @@ func (r *Renderer) MapQuad4(target, source *ebiten.Image, quad [4]PointF32) {
 		2, 3, 4,
 		3, 0, 4,
 	}
-	target.DrawTrianglesShader(r.vertices[:], indices, shaderMapQuad4, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], indices, shaderMapQuad4)
 	r.opts.Images[0] = nil
 	r.vertices = r.vertices[:4]
 }
@@ -83,10 +85,9 @@ func (r *Renderer) MapProjective(target, source *ebiten.Image, quad [4]PointF32)
 	for i, pt := range quad {
 		r.vertices[i].DstX = minX + pt.X
 		r.vertices[i].DstY = minY + pt.Y
+		r.vertices[i].SrcX = pt.X
+		r.vertices[i].SrcY = pt.Y
 	}
-
-	minX, minY, srcWidth, srcHeight := rectOriginSizeF32(source.Bounds())
-	r.setSrcRectCoords(minX, minY, minX+srcWidth, minY+srcHeight)
 	r.opts.Uniforms["Homography"] = [9]float32{ // use column-major order
 		homography[0], homography[3], homography[6],
 		homography[1], homography[4], homography[7],
@@ -94,7 +95,7 @@ func (r *Renderer) MapProjective(target, source *ebiten.Image, quad [4]PointF32)
 	}
 	r.opts.Images[0] = source
 	ensureShaderMapProjectiveLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderMapProjective, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderMapProjective)
 	r.opts.Images[0] = nil
 	clear(r.opts.Uniforms)
 }
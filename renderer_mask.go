@@ -20,13 +20,15 @@ func (r *Renderer) Mask(target, source, mask *ebiten.Image, ox, oy float32) {
 	r.opts.Images[0] = source
 	r.opts.Images[1] = mask
 	ensureShaderMaskLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderMask, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderMask)
 	r.opts.Images[0] = nil
 	r.opts.Images[1] = nil
 }
 
 // MaskAt draws 'source' over 'target' using 'mask' as an alpha mask at the given position.
 // If you want the mask to be fit to the source instead, see [Renderer.Mask]().
+// Pixels outside the mask bounds are treated as transparent unless clamped with
+// [Renderer.SetSourceClamping]().
 func (r *Renderer) MaskAt(target, source, mask *ebiten.Image, ox, oy, oxMask, oyMask float32) {
 	srcOX, srcOY, srcWidthF32, srcHeightF32 := rectOriginSizeF32(source.Bounds())
 	dstOX, dstOY := rectOriginF32(target.Bounds())
@@ -35,10 +37,12 @@ func (r *Renderer) MaskAt(target, source, mask *ebiten.Image, ox, oy, oxMask, oy
 	r.setSrcRectCoords(srcOX, srcOY, srcOX+srcWidthF32, srcOY+srcHeightF32)
 
 	r.setFlatCustomVAs01(ox-oxMask, oy-oyMask)
+	r.setClampingUniform()
 	r.opts.Images[0] = source
 	r.opts.Images[1] = mask
 	ensureShaderMaskAtLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderMaskAt, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderMaskAt)
+	clear(r.opts.Uniforms)
 	r.opts.Images[0] = nil
 	r.opts.Images[1] = nil
 }
@@ -62,7 +66,7 @@ func (r *Renderer) MaskThreshold(target, source, mask *ebiten.Image, reveal, ox,
 	r.opts.Images[0] = source
 	r.opts.Images[1] = mask
 	ensureShaderMaskThresholdLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderMaskThreshold, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderMaskThreshold)
 	r.opts.Images[0] = nil
 	r.opts.Images[1] = nil
 }
@@ -71,7 +75,8 @@ func (r *Renderer) MaskThreshold(target, source, mask *ebiten.Image, reveal, ox,
 // the given points.
 func (r *Renderer) MaskHorz(target, source *ebiten.Image, x, y, inX, outX float32) {
 	ensureShaderMaskHorzLoaded()
-	r.setFlatCustomVAs01(inX, outX)
+	srcOX := float32(source.Bounds().Min.X)
+	r.setFlatCustomVAs01(inX-x+srcOX, outX-x+srcOX)
 	r.DrawShaderAt(target, source, x, y, 0, 0, shaderMaskHorz)
 }
 
@@ -90,19 +95,25 @@ func (r *Renderer) MaskCircle(target, source *ebiten.Image, cx, cy, srcOffsetX,
 	}
 
 	srcOX, srcOY, srcWidthF32, srcHeightF32 := rectOriginSizeF32(source.Bounds())
-	ox, oy := cx-srcWidthF32/2.0+srcOffsetX, cy-srcHeightF32/2.0+srcOffsetY
+	ox, oy := maskCircleOrigin(srcWidthF32, srcHeightF32, cx, cy, srcOffsetX, srcOffsetY)
 	dstOX, dstOY := rectOriginF32(target.Bounds())
 	dstOX, dstOY = dstOX+ox, dstOY+oy
 	r.setDstRectCoords(dstOX, dstOY, dstOX+srcWidthF32, dstOY+srcHeightF32)
 	r.setSrcRectCoords(srcOX, srcOY, srcOX+srcWidthF32, srcOY+srcHeightF32)
 
 	r.opts.Images[0] = source
-	r.setFlatCustomVAs(cx, cy, hardRadius, softEdge)
+	r.setFlatCustomVAs(cx-ox+srcOX, cy-oy+srcOY, hardRadius, softEdge)
 	ensureShaderMaskCircleLoaded()
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderMaskCircle, &r.opts)
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderMaskCircle)
 	r.opts.Images[0] = nil
 }
 
+// maskCircleOrigin returns the position where [Renderer.MaskCircle]()
+// draws a source of the given size.
+func maskCircleOrigin(srcWidth, srcHeight, cx, cy, srcOffsetX, srcOffsetY float32) (ox, oy float32) {
+	return cx - srcWidth/2.0 + srcOffsetX, cy - srcHeight/2.0 + srcOffsetY
+}
+
 // Related to DrawAlphaMaskCirc
 type AlphaMaskPattern int
 
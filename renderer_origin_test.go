@@ -0,0 +1,356 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testStripesShader draws vertical stripes in source coordinates.
+const testStripesShader = `//kage:unit pixels
+package main
+
+func Fragment(_ vec4, sourceCoords vec2, color vec4, _ vec4) vec4 {
+	return color * (0.5 + 0.5*fract(sourceCoords.x/8.0))
+}
+`
+
+// go test -run ^TestTargetOrigins$ . -count 1
+func TestTargetOrigins(t *testing.T) {
+	const w, h = 96, 72
+	const offX, offY = 37, 23
+
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	quad := [4]PointF32{{X: 14, Y: 10}, {X: 80, Y: 18}, {X: 70, Y: 64}, {X: 22, Y: 56}}
+
+	var mask, source, over, jfmap *ebiten.Image
+	var shader *ebiten.Shader
+	cases := []struct {
+		name string
+		draw func(r *Renderer, target *ebiten.Image)
+	}{
+		{"DrawArea", func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, 10, 8, 40, 30, 6) }},
+		{"DrawIntArea", func(r *Renderer, target *ebiten.Image) { r.DrawIntArea(target, 5, 5, 20, 12) }},
+		{"StrokeIntArea", func(r *Renderer, target *ebiten.Image) { r.StrokeIntArea(target, 20, 20, 30, 20, 2, 2) }},
+		{"StrokeArea", func(r *Renderer, target *ebiten.Image) { r.StrokeArea(target, 12, 10, 50, 40, 2, 2, 4) }},
+		{"DrawLine", func(r *Renderer, target *ebiten.Image) { r.DrawLine(target, 8, 60, 80, 12, 5) }},
+		{"DrawTaperedLine", func(r *Renderer, target *ebiten.Image) {
+			r.DrawTaperedLine(target, 8, 8, 80, 60, 2, 8, color.White, red)
+		}},
+		{"DrawCircle", func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, 48, 36, 20) }},
+		{"StrokeCircle", func(r *Renderer, target *ebiten.Image) { r.StrokeCircle(target, 48, 36, 20, 4) }},
+		{"DrawRing", func(r *Renderer, target *ebiten.Image) { r.DrawRing(target, 48, 36, 12, 24) }},
+		{"DrawRingSector", func(r *Renderer, target *ebiten.Image) {
+			r.DrawRingSector(target, 48, 36, 12, 30, 0.3, 2.5, 0)
+		}},
+		{"StrokeRingSector", func(r *Renderer, target *ebiten.Image) {
+			r.StrokeRingSector(target, 48, 36, 12, 30, 3, 0.3, 2.5, 0)
+		}},
+		{"DrawPie", func(r *Renderer, target *ebiten.Image) { r.DrawPie(target, 48, 36, 28, 0.5, 2.8, -3) }},
+		{"StrokePie", func(r *Renderer, target *ebiten.Image) { r.StrokePie(target, 48, 36, 28, 3, 0.5, 2.8, 0) }},
+		{"DrawEllipse", func(r *Renderer, target *ebiten.Image) { r.DrawEllipse(target, 48, 36, 30, 16, 0.4) }},
+		{"DrawTriangle", func(r *Renderer, target *ebiten.Image) { r.DrawTriangle(target, 10, 60, 48, 8, 86, 60, -4) }},
+		{"StrokeTriangle", func(r *Renderer, target *ebiten.Image) {
+			r.StrokeTriangle(target, 10, 60, 48, 8, 86, 60, 3, 0)
+		}},
+		{"DrawTriangleColors", func(r *Renderer, target *ebiten.Image) {
+			r.DrawTriangleColors(target, 10, 60, 48, 8, 86, 60, 0, red, green, blue)
+		}},
+		{"DrawHexagon", func(r *Renderer, target *ebiten.Image) { r.DrawHexagon(target, 48, 36, 26, -4, 0.2) }},
+		{"DrawQuad", func(r *Renderer, target *ebiten.Image) { r.DrawQuad(target, quad, 2) }},
+		{"DrawMetaballs", func(r *Renderer, target *ebiten.Image) {
+			r.DrawMetaballs(target, []PointF32{{X: 36, Y: 36}, {X: 60, Y: 36}}, []float32{14, 12}, 1.0, AAMargin)
+		}},
+		{"DrawRibbon", func(r *Renderer, target *ebiten.Image) {
+			pts := []PointF32{{X: 8, Y: 60}, {X: 40, Y: 12}, {X: 88, Y: 40}}
+			r.DrawRibbon(target, pts, []float32{4, 10, 6}, nil)
+		}},
+		{"DrawParametric", func(r *Renderer, target *ebiten.Image) {
+			lissajous := func(t float64) PointF32 {
+				return PointF32{X: 48 + 36*float32(math.Sin(3*t)), Y: 36 + 28*float32(math.Sin(2*t))}
+			}
+			r.DrawParametric(target, lissajous, 0, 2*math.Pi, 3)
+		}},
+		{"DrawSDF", func(r *Renderer, target *ebiten.Image) {
+			r.DrawSDF(target, SDFSmoothUnion(SDFCircle(36, 36, 18), SDFBox(48, 20, 36, 30), 8))
+		}},
+		{"Scale", func(r *Renderer, target *ebiten.Image) { r.Scale(target, mask, 20, 10, 1.5, false) }},
+		{"FlatPaint", func(r *Renderer, target *ebiten.Image) { r.FlatPaint(target, mask, 20, 10) }},
+		{"ApplyExpansion", func(r *Renderer, target *ebiten.Image) { r.ApplyExpansion(target, mask, 20, 10, 4) }},
+		{"ApplyExpansionRect", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyExpansionRect(target, mask, 20, 10, 4)
+		}},
+		{"ApplyOutline", func(r *Renderer, target *ebiten.Image) { r.ApplyOutline(target, mask, 20, 10, 3) }},
+		{"ApplyBlur", func(r *Renderer, target *ebiten.Image) { r.ApplyBlur(target, mask, 20, 10, 4, 1) }},
+		{"ApplyShadow", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyShadow(target, mask, 20, 10, 4, 4, 3, ClampNone)
+		}},
+		{"ApplyHorzGlow", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyHorzGlow(target, mask, 20, 10, 6, 0.1, 0.9, 0.5)
+		}},
+		{"ApplyDarkHorzGlow", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyDarkHorzGlow(target, mask, 20, 10, 6, 0.1, 0.9, 0.5)
+		}},
+		{"Gradient", func(r *Renderer, target *ebiten.Image) {
+			r.Gradient(target, mask, 20, 10, red, blue, 8, 0.5, 1)
+		}},
+		{"GradientRadial", func(r *Renderer, target *ebiten.Image) {
+			r.GradientRadial(target, 48, 36, red, blue, 4, 12, 30, 8, 1)
+		}},
+		{"Mask", func(r *Renderer, target *ebiten.Image) { r.Mask(target, source, mask, 20, 10) }},
+		{"MaskCircle", func(r *Renderer, target *ebiten.Image) { r.MaskCircle(target, source, 48, 36, 0, 0, 14, 4) }},
+		{"MapQuad4", func(r *Renderer, target *ebiten.Image) { r.MapQuad4(target, source, quad) }},
+		{"MapProjective", func(r *Renderer, target *ebiten.Image) { r.MapProjective(target, source, quad) }},
+		{"WarpArc", func(r *Renderer, target *ebiten.Image) { r.WarpArc(target, source, 48, 70, 56, RadsTop) }},
+		{"WarpBarrel", func(r *Renderer, target *ebiten.Image) { r.WarpBarrel(target, source, 20, 10, 6, 4) }},
+		{"DrawIntRect", func(r *Renderer, target *ebiten.Image) { r.DrawIntRect(target, image.Rect(5, 5, 25, 17)) }},
+		{"StrokeIntRect", func(r *Renderer, target *ebiten.Image) {
+			r.StrokeIntRect(target, image.Rect(20, 20, 50, 40), 2, 2)
+		}},
+		{"DrawRect", func(r *Renderer, target *ebiten.Image) { r.DrawRect(target, image.Rect(10, 8, 50, 38), -6) }},
+		{"StrokeRect", func(r *Renderer, target *ebiten.Image) {
+			r.StrokeRect(target, image.Rect(12, 10, 62, 50), 2, 2, 4)
+		}},
+		{"DrawPieRate", func(r *Renderer, target *ebiten.Image) { r.DrawPieRate(target, 48, 36, 28, RadsTop, 0.3, -3) }},
+		{"StrokePieRate", func(r *Renderer, target *ebiten.Image) {
+			r.StrokePieRate(target, 48, 36, 28, 3, RadsTop, 0.3, 0)
+		}},
+		{"DrawQuadSoft", func(r *Renderer, target *ebiten.Image) { r.DrawQuadSoft(target, quad, -4, 3) }},
+		{"ScaleAlphaBy", func(r *Renderer, target *ebiten.Image) {
+			r.Push()
+			r.ScaleAlphaBy(0.5)
+			r.DrawCircle(target, 48, 36, 20)
+			r.Pop()
+		}},
+		{"DrawShader", func(r *Renderer, target *ebiten.Image) { r.DrawShader(target, 0, 0, shader) }},
+		{"DrawRectShader", func(r *Renderer, target *ebiten.Image) { r.DrawRectShader(target, 20, 10, 40, 30, 2, 2, shader) }},
+		{"DrawShaderAt", func(r *Renderer, target *ebiten.Image) { r.DrawShaderAt(target, source, 20, 10, 0, 0, shader) }},
+		{"Noise", func(r *Renderer, target *ebiten.Image) { r.Noise(target, 0.8, 0.26, 0.3) }},
+		{"NoiseGolden", func(r *Renderer, target *ebiten.Image) { r.NoiseGolden(target, 1.0, 1.0, 0.5) }},
+		{"TileDotsGrid", func(r *Renderer, target *ebiten.Image) { r.TileDotsGrid(target, 3, 10, 2, 1) }},
+		{"TileDotsHex", func(r *Renderer, target *ebiten.Image) { r.TileDotsHex(target, 3, 12, 2, 1) }},
+		{"TileRectsGrid", func(r *Renderer, target *ebiten.Image) { r.TileRectsGrid(target, 16, 12, 10, 6, 3, 2) }},
+		{"TileTriHex", func(r *Renderer, target *ebiten.Image) { r.TileTriHex(target, 16, 10, 3, 2) }},
+		{"TileTriUpGrid", func(r *Renderer, target *ebiten.Image) { r.TileTriUpGrid(target, 16, 10, 3, 2) }},
+		{"HalftoneTri", func(r *Renderer, target *ebiten.Image) { r.HalftoneTri(target, source, 20, 10, 8, 2, 7, 1, 2) }},
+		{"ApplyScanlinesSharp", func(r *Renderer, target *ebiten.Image) { r.ApplyScanlinesSharp(target, 2, 3, 0.8, 1) }},
+		{"ApplyWaveLines", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyWaveLines(target, 6, 0.2, 0.8, 4, 3, DirRadsLTR+0.3)
+		}},
+		{"ApplyErosion", func(r *Renderer, target *ebiten.Image) { r.ApplyErosion(target, mask, 20, 10, 3) }},
+		{"ApplyBlur2", func(r *Renderer, target *ebiten.Image) { r.ApplyBlur2(target, mask, 20, 10, 8, 1) }},
+		{"ApplyVertBlur", func(r *Renderer, target *ebiten.Image) { r.ApplyVertBlur(target, mask, 20, 10, 4, 1) }},
+		{"ApplyHorzBlur", func(r *Renderer, target *ebiten.Image) { r.ApplyHorzBlur(target, mask, 20, 10, 4, 1) }},
+		{"ApplyBlurD4", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyBlurD4(target, mask, 20, 10, GaussKern7, GaussKern5, 1)
+		}},
+		{"ApplyHardShadow", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyHardShadow(target, mask, 20, 10, 4, 4, ClampNone)
+		}},
+		{"ApplyZoomShadow", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyZoomShadow(target, mask, 20, 10, 2, 2, 1.3, ClampNone)
+		}},
+		{"ApplyGlow", func(r *Renderer, target *ebiten.Image) { r.ApplyGlow(target, mask, 20, 10, 6, 6, 0.1, 0.9, 0.5) }},
+		{"ApplySimpleGlow", func(r *Renderer, target *ebiten.Image) { r.ApplySimpleGlow(target, mask, 20, 10, 6) }},
+		{"ApplyGlowD4", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyGlowD4(target, mask, 20, 10, GaussKern5, GaussKern5, 0.1, 0.9, 0.5)
+		}},
+		{"ApplyColorGlowD4", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyColorGlowD4(target, mask, 20, 10, GaussKern5, GaussKern5, RGBF32(red), 0.1, 0.9, 0.5)
+		}},
+		{"ApplyToFrames", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyToFrames(target, source, 16, 14, 4, func(r *Renderer, target, frame *ebiten.Image, ox, oy float32) {
+				r.ApplyOutline(target, frame, ox, oy, 2)
+			})
+		}},
+		{"JFMHeat", func(r *Renderer, target *ebiten.Image) { r.JFMHeat(target, jfmap, 20, 10, 16) }},
+		{"JFMExpand", func(r *Renderer, target *ebiten.Image) { r.JFMExpand(target, mask, nil, 20, 10, 4, AAMargin) }},
+		{"JFMErode", func(r *Renderer, target *ebiten.Image) { r.JFMErode(target, mask, nil, 20, 10, 3, AAMargin) }},
+		// JFMOutline and JFMInsetContour are not implemented yet
+		{"SimpleGradient", func(r *Renderer, target *ebiten.Image) { r.SimpleGradient(target, red, blue, DirRadsBRTL) }},
+		{"ColorMix", func(r *Renderer, target *ebiten.Image) { r.ColorMix(target, source, over, 20, 10, 0.8, 0.4) }},
+		{"OklabShift", func(r *Renderer, target *ebiten.Image) { r.OklabShift(target, source, 20, 10, 0.1, 0.05, 1.0) }},
+		{"ColorizeByLightness", func(r *Renderer, target *ebiten.Image) {
+			r.ColorizeByLightness(target, source, 20, 10, red, blue, 0.2, 0.8, 4, 1.0)
+		}},
+		{"DitherMat4", func(r *Renderer, target *ebiten.Image) {
+			r.DitherMat4(target, source, 20, 10, 1, 2, DitherBW4, DitherBayes, 0, 0)
+		}},
+		{"MaskAt", func(r *Renderer, target *ebiten.Image) { r.MaskAt(target, source, mask, 20, 10, 24, 12) }},
+		{"MaskHorz", func(r *Renderer, target *ebiten.Image) { r.MaskHorz(target, source, 20, 10, 30, 44) }},
+		{"MaskThreshold", func(r *Renderer, target *ebiten.Image) { r.MaskThreshold(target, source, over, 0.5, 20, 10) }},
+		{"DrawAlphaMaskCirc", func(r *Renderer, target *ebiten.Image) {
+			r.DrawAlphaMaskCirc(target, 48, 36, 30, 0.2, MaskPatternEllipseCuts)
+		}},
+		{"BeginClip", func(r *Renderer, target *ebiten.Image) {
+			layer := r.BeginClip(target, SDFCircle(48, 36, 24))
+			r.DrawArea(layer, 10, 8, 76, 56, 0)
+			r.EndClip()
+		}},
+		{"BeginClipMask", func(r *Renderer, target *ebiten.Image) {
+			layer := r.BeginClipMask(target, mask, 20, 10)
+			r.DrawArea(layer, 10, 8, 76, 56, 0)
+			r.EndClip()
+		}},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		mask = r.NewCircle(14)
+		source = r.NewSimpleGradient(32, 28, red, blue, 0.5)
+		over = r.NewSimpleGradient(32, 28, green, red, 2.0)
+		jfmap = ebiten.NewImage(mask.Bounds().Dx(), mask.Bounds().Dy())
+		r.JFMCompute(jfmap, mask, JFMBoundary, 16, 0.001, 1.0)
+		var err error
+		if shader, err = ebiten.NewShader([]byte(testStripesShader)); err != nil {
+			fail("%v", err)
+			return
+		}
+
+		parent := ebiten.NewImage(w+offX*2, h+offY*2)
+		sub := parent.SubImage(image.Rect(offX, offY, offX+w, offY+h)).(*ebiten.Image)
+		ref := ebiten.NewImage(w, h)
+		refPix := make([]byte, w*h*4)
+		subPix := make([]byte, w*h*4)
+		for _, c := range cases {
+			ref.Clear()
+			parent.Clear()
+			c.draw(r, ref)
+			c.draw(r, sub)
+			ref.ReadPixels(refPix)
+			sub.ReadPixels(subPix)
+			if msg := comparePixels(refPix, subPix, w, 2); msg != "" {
+				fail("%s: %s", c.name, msg)
+			} else if isTransparent(refPix) {
+				fail("%s: reference output is fully transparent", c.name)
+			}
+		}
+	})
+}
+
+// go test -run ^TestSubimageSources$ . -count 1
+func TestSubimageSources(t *testing.T) {
+	const size = 160
+	const tile = 40
+
+	cases := []struct {
+		name string
+		draw func(r *Renderer, target, mask *ebiten.Image)
+	}{
+		{"ApplyExpansion", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyExpansion(target, mask, 50, 50, 6) }},
+		{"ApplyExpansionRect", func(r *Renderer, target, mask *ebiten.Image) {
+			r.ApplyExpansionRect(target, mask, 50, 50, 6)
+		}},
+		{"ApplyErosion", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyErosion(target, mask, 50, 50, 4) }},
+		{"ApplyOutline", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyOutline(target, mask, 50, 50, 4) }},
+		{"ApplyBlur", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyBlur(target, mask, 50, 50, 8, 1) }},
+		{"ApplyBlur2", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyBlur2(target, mask, 50, 50, 12, 1) }},
+		{"ApplyVertBlur", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyVertBlur(target, mask, 50, 50, 8, 1) }},
+		{"ApplyHorzBlur", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyHorzBlur(target, mask, 50, 50, 8, 1) }},
+		{"ApplyHardShadow", func(r *Renderer, target, mask *ebiten.Image) {
+			r.ApplyHardShadow(target, mask, 50, 50, 6, 6, ClampNone)
+		}},
+		{"ApplyShadow", func(r *Renderer, target, mask *ebiten.Image) {
+			r.ApplyShadow(target, mask, 50, 50, 6, 6, 8, ClampNone)
+		}},
+		{"ApplyZoomShadow", func(r *Renderer, target, mask *ebiten.Image) {
+			r.ApplyZoomShadow(target, mask, 50, 50, 2, 2, 1.3, ClampNone)
+		}},
+		{"ApplyGlow", func(r *Renderer, target, mask *ebiten.Image) {
+			r.ApplyGlow(target, mask, 50, 50, 10, 10, 0, 0.5, 1)
+		}},
+		{"ApplyHorzGlow", func(r *Renderer, target, mask *ebiten.Image) {
+			r.ApplyHorzGlow(target, mask, 50, 50, 10, 0, 0.5, 1)
+		}},
+		{"ApplyBlurD4", func(r *Renderer, target, mask *ebiten.Image) {
+			r.ApplyBlurD4(target, mask, 50, 50, GaussKern7, GaussKern5, 1)
+		}},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+
+		// sprite sheet with opaque neighbors around the center tile
+		sheet := ebiten.NewImage(tile*3, tile*3)
+		sheet.Fill(color.RGBA{0, 0, 255, 255})
+		subMask := sheet.SubImage(image.Rect(tile, tile, tile*2, tile*2)).(*ebiten.Image)
+		subMask.Clear()
+		r.DrawCircle(subMask, tile/2, tile/2, tile/2)
+		isolated := ebiten.NewImage(tile, tile)
+		r.DrawCircle(isolated, tile/2, tile/2, tile/2)
+
+		ref := ebiten.NewImage(size, size)
+		out := ebiten.NewImage(size, size)
+		refPix := make([]byte, size*size*4)
+		outPix := make([]byte, size*size*4)
+		for _, c := range cases {
+			ref.Clear()
+			out.Clear()
+			c.draw(r, ref, isolated)
+			c.draw(r, out, subMask)
+			ref.ReadPixels(refPix)
+			out.ReadPixels(outPix)
+			if msg := comparePixels(refPix, outPix, size, 2); msg != "" {
+				fail("%s: %s", c.name, msg)
+			} else if isTransparent(refPix) {
+				fail("%s: reference output is fully transparent", c.name)
+			}
+		}
+	})
+}
+
+// go test -run ^TestSourceClamping$ . -count 1
+func TestSourceClamping(t *testing.T) {
+	const size = 160
+	const tile = 40
+
+	cases := []struct {
+		name string
+		draw func(r *Renderer, target, mask *ebiten.Image)
+	}{
+		{"ApplyBlur", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyBlur(target, mask, 50, 50, 8, 1) }},
+		{"ApplyBlur2", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyBlur2(target, mask, 50, 50, 12, 1) }},
+		{"ApplyVertBlur", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyVertBlur(target, mask, 50, 50, 8, 1) }},
+		{"ApplyHorzBlur", func(r *Renderer, target, mask *ebiten.Image) { r.ApplyHorzBlur(target, mask, 50, 50, 8, 1) }},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		r.SetSourceClamping(ClampAll)
+
+		// opaque red tile surrounded by opaque blue tiles
+		sheet := ebiten.NewImage(tile*3, tile*3)
+		sheet.Fill(color.RGBA{0, 0, 255, 255})
+		mask := sheet.SubImage(image.Rect(tile, tile, tile*2, tile*2)).(*ebiten.Image)
+		mask.Fill(color.RGBA{255, 0, 0, 255})
+
+		img := ebiten.NewImage(size, size)
+		pix := make([]byte, size*size*4)
+		maskArea := image.Rect(50, 50, 50+tile, 50+tile)
+		for _, c := range cases {
+			img.Clear()
+			c.draw(r, img, mask)
+			img.ReadPixels(pix)
+		pixels:
+			for y := range size {
+				for x := range size {
+					rgba := pix[(y*size+x)*4 : (y*size+x)*4+4]
+					inside := image.Pt(x, y).In(maskArea)
+					if inside && (rgba[0] < 250 || rgba[2] > 5 || rgba[3] < 250) {
+						fail("%s: pixel (%d, %d) = %v, want opaque red", c.name, x, y, rgba)
+						break pixels
+					}
+					if !inside && rgba[3] != 0 {
+						fail("%s: pixel (%d, %d) drawn outside of the mask", c.name, x, y)
+						break pixels
+					}
+				}
+			}
+		}
+	})
+}
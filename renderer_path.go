@@ -0,0 +1,237 @@
+package shapes
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// MaxRibbonPoints is the maximum number of points accepted by [Renderer.DrawRibbon]().
+// This is limited by the 16-bit indices used in Ebitengine triangle draws.
+const MaxRibbonPoints = 32768
+
+// ribbonMiterLimit limits how far the ribbon joints can extend at sharp turns,
+// as a factor of the joint's half width.
+const ribbonMiterLimit = 4.0
+
+// DrawRibbon draws a smooth strip along the given path, with per-point widths and
+// colors linearly interpolated between points. If colors is nil, the renderer's
+// color (vertex 0) is used for the whole ribbon. This is typically used for trails,
+// sword swings and similar effects.
+//
+// The ribbon is drawn as a single triangle strip with antialiased sides, so unlike
+// chaining [Renderer.DrawLine]() calls, joints don't accumulate alpha (only
+// self-intersections and very sharp turns may overlap). Ends are flat; use zero
+// widths at the ends for tapered trails.
+//
+// The function panics if the lengths of widths (or colors, if not nil) don't match
+// the number of points, or if more than [MaxRibbonPoints] are given.
+func (r *Renderer) DrawRibbon(target *ebiten.Image, pts []PointF32, widths []float32, colors []color.Color) {
+	if len(widths) != len(pts) {
+		panic("len(widths) != len(pts)")
+	}
+	if colors != nil && len(colors) != len(pts) {
+		panic("colors != nil && len(colors) != len(pts)")
+	}
+	if len(pts) > MaxRibbonPoints {
+		panic("len(pts) > MaxRibbonPoints")
+	}
+	if len(pts) < 2 {
+		return // nothing to draw
+	}
+
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	vertices := r.getAuxVertices(len(pts) * 2)
+	if !r.setRibbonGeometry(dstOX, dstOY, vertices, pts, widths) {
+		return // degenerate path
+	}
+	if colors == nil {
+		clr := r.GetColorF32()
+		for i := range vertices {
+			vertices[i].ColorR, vertices[i].ColorG = clr[0], clr[1]
+			vertices[i].ColorB, vertices[i].ColorA = clr[2], clr[3]
+		}
+	} else {
+		for i, c := range colors {
+			clr := ColorToF32(c)
+			for j := i * 2; j < i*2+2; j++ {
+				vertices[j].ColorR, vertices[j].ColorG = clr[0], clr[1]
+				vertices[j].ColorB, vertices[j].ColorA = clr[2], clr[3]
+			}
+		}
+	}
+
+	ensureShaderRibbonLoaded()
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, vertices, r.ribbonIndices(len(pts)), shaderRibbon)
+	clear(r.opts.Uniforms)
+}
+
+// setRibbonGeometry sets all vertex fields except colors for the given path.
+// Vertices 2*i and 2*i + 1 are the left and right sides of pts[i]. Custom
+// VAs are set to (side [-1, +1], half width perpendicular to the segments,
+// distance from start, distance to end), and the target's origin must be
+// given as (dstOX, dstOY). Returns false if the path has no length.
+func (r *Renderer) setRibbonGeometry(dstOX, dstOY float32, vertices []ebiten.Vertex, pts []PointF32, widths []float32) bool {
+	// find the first non-degenerate segment direction to use
+	// as a fallback for leading duplicated points
+	var prevDir PointF32
+	var totalLen float32
+	for i := 1; i < len(pts); i++ {
+		segLen := pts[i].Sub(pts[i-1]).Length()
+		if prevDir == (PointF32{}) && segLen > 0 {
+			prevDir = pts[i].Sub(pts[i-1]).Normalize()
+		}
+		totalLen += segLen
+	}
+	if totalLen == 0 {
+		return false
+	}
+
+	var dist float32
+	for i, pt := range pts {
+		nextDir := prevDir
+		if i+1 < len(pts) {
+			if seg := pts[i+1].Sub(pt); seg.Length() > 0 {
+				nextDir = seg.Normalize()
+			}
+		}
+		if i > 0 {
+			dist += pt.Sub(pts[i-1]).Length()
+		}
+
+		// miter offset: averaged normal, scaled to preserve the width
+		prevNormal := PointF32{X: -prevDir.Y, Y: prevDir.X}
+		nextNormal := PointF32{X: -nextDir.Y, Y: nextDir.X}
+		miter := prevNormal.Add(nextNormal).Normalize()
+		if miter == (PointF32{}) {
+			miter = nextNormal // 180 degree turn
+		}
+		miterCos := miter.Dot(nextNormal)
+		scale := 1.0 / max(miterCos, 1.0/ribbonMiterLimit)
+		halfWidth := max(widths[i], 0) / 2.0
+		offset := miter.Scale(halfWidth * scale)
+
+		// the side distance must be measured perpendicular to the segments,
+		// not along the miter, or the antialiasing would get softer at sharp
+		// joints. This is the half width unless the miter limit kicks in
+		sideHalfWidth := halfWidth * scale * miterCos
+		for j, side := range [2]float32{-1, +1} {
+			v := &vertices[i*2+j]
+			v.DstX = dstOX + pt.X + offset.X*side
+			v.DstY = dstOY + pt.Y + offset.Y*side
+			v.SrcX, v.SrcY = 0, 0
+			v.Custom0, v.Custom1 = side, sideHalfWidth
+			v.Custom2, v.Custom3 = dist, totalLen-dist
+		}
+		prevDir = nextDir
+	}
+	return true
+}
+
+// ribbonIndices returns the triangle strip indices for a ribbon of numPoints.
+func (r *Renderer) ribbonIndices(numPoints int) []uint16 {
+	indices := r.getAuxIndices((numPoints - 1) * 6)
+	for i := range numPoints - 1 {
+		left, right := uint16(i*2), uint16(i*2+1)
+		indices[i*6+0], indices[i*6+1], indices[i*6+2] = left, left+2, right
+		indices[i*6+3], indices[i*6+4], indices[i*6+5] = right, left+2, right+2
+	}
+	return indices
+}
+
+// parametricInitialSegments is the number of segments sampled by
+// [Renderer.DrawParametric]() before starting the adaptive sampling.
+const parametricInitialSegments = 16
+
+// parametricMaxDepth is the maximum recursion depth for the adaptive
+// sampling of [Renderer.DrawParametric](). Each initial segment can
+// produce up to 2^parametricMaxDepth samples, so this is the highest
+// depth that keeps the worst case within [MaxRibbonPoints].
+const parametricMaxDepth = 10
+
+// DrawParametric draws a smooth stroke of the given thickness following
+// the curve f(t) for t in [t0, t1]. The curve is sampled adaptively based
+// on its curvature and screen-space error, and stroked seamlessly as a
+// single ribbon (see [Renderer.DrawRibbon]()) with the renderer's color.
+// Like ribbons, the stroke ends are flat. The sampling depth is limited,
+// so curves that oscillate too fast are drawn with less precision, and
+// points where f returns NaN are skipped.
+//
+// Coordinates returned by f are relative to the target's origin, like in
+// all other drawing functions.
+func (r *Renderer) DrawParametric(target *ebiten.Image, f func(t float64) PointF32, t0, t1 float64, thickness float32) {
+	if thickness <= 0 || t0 == t1 {
+		return // nothing to draw
+	}
+	r.setParametricPath(f, t0, t1, thickness)
+	r.DrawRibbon(target, r.auxPoints, r.auxWidths, nil)
+}
+
+// setParametricPath samples the curve into r.auxPoints, with the
+// matching r.auxWidths, as drawn by [Renderer.DrawParametric]().
+func (r *Renderer) setParametricPath(f func(t float64) PointF32, t0, t1 float64, thickness float32) {
+	ta, pa := t0, f(t0)
+	r.auxPoints = r.auxPoints[:0]
+	if !isNaNPoint(pa) {
+		r.auxPoints = append(r.auxPoints, pa)
+	}
+	step := (t1 - t0) / parametricInitialSegments
+	for i := range parametricInitialSegments {
+		tb := t0 + step*float64(i+1)
+		if i == parametricInitialSegments-1 {
+			tb = t1
+		}
+		pb := f(tb)
+		r.sampleParametric(f, ta, tb, pa, pb, 0)
+		ta, pa = tb, pb
+	}
+
+	r.auxWidths = r.auxWidths[:0]
+	for range r.auxPoints {
+		r.auxWidths = append(r.auxWidths, thickness)
+	}
+}
+
+// sampleParametric appends the points in (ta, tb] to r.auxPoints, recursively
+// subdividing the interval while the midpoint deviates from the chord by more
+// than a fraction of a pixel or the curve turns too sharply.
+func (r *Renderer) sampleParametric(f func(t float64) PointF32, ta, tb float64, pa, pb PointF32, depth int) {
+	const maxDeviation = 0.25           // in pixels
+	const maxTurnCos = 0.99             // ~8 degrees
+	const minSegmentLen = 0.5           // in pixels
+	const maxSegmentLen float32 = 256.0 // in pixels
+
+	tm := (ta + tb) / 2.0
+	pm := f(tm)
+	if depth < parametricMaxDepth {
+		chord := pb.Sub(pa)
+		chordLen := chord.Length()
+		var deviation float32
+		if chordLen > 0 {
+			deviation = abs(chord.X*(pm.Y-pa.Y)-chord.Y*(pm.X-pa.X)) / chordLen
+		} else {
+			deviation = pm.Sub(pa).Length()
+		}
+		da, db := pm.Sub(pa), pb.Sub(pm)
+		lenA, lenB := da.Length(), db.Length()
+		turnCos := float32(1.0)
+		if lenA >= minSegmentLen && lenB >= minSegmentLen {
+			turnCos = da.Dot(db) / (lenA * lenB)
+		}
+		if deviation > maxDeviation || turnCos < maxTurnCos || chordLen > maxSegmentLen {
+			r.sampleParametric(f, ta, tm, pa, pm, depth+1)
+			r.sampleParametric(f, tm, tb, pm, pb, depth+1)
+			return
+		}
+	}
+	if isNaNPoint(pb) {
+		return // skip undefined points
+	}
+	r.auxPoints = append(r.auxPoints, pb)
+}
+
+func isNaNPoint(pt PointF32) bool {
+	return math.IsNaN(float64(pt.X)) || math.IsNaN(float64(pt.Y))
+}
@@ -0,0 +1,149 @@
+package shapes
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// go test -run ^TestDrawRibbon$ . -count 1
+func TestDrawRibbon(t *testing.T) {
+	const NumPoints = 48
+	var pts [NumPoints]PointF32
+	var widths [NumPoints]float32
+	var colors [NumPoints]color.Color
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		lx, ly := ctx.LeftClickF32()
+		rx, ry := ctx.RightClickF32()
+		phase := ctx.ModAnim(2*math.Pi, 2.0)
+		for i := range NumPoints {
+			t := float32(i) / (NumPoints - 1)
+			wave := float32(48.0 * math.Sin(phase+float64(t)*3*math.Pi))
+			pts[i] = PointF32{X: lerp(lx, rx, t), Y: lerp(ly, ry, t) + wave}
+			widths[i] = 32.0 * float32(math.Sin(float64(t)*math.Pi))
+			a := uint8(255 * t)
+			colors[i] = color.RGBA{a, a / 2, 255 - a, a}
+		}
+		ctx.Renderer.DrawRibbon(canvas, pts[:], widths[:], colors[:])
+
+		// sharp turns and constant width with renderer color
+		ctx.Renderer.SetColorF32(0.5, 0.5, 0.5, 0.5)
+		zigzag := []PointF32{{64, 64}, {128, 128}, {192, 64}, {256, 128}, {200, 132}}
+		ctx.Renderer.DrawRibbon(canvas, zigzag, []float32{8, 8, 8, 8, 8}, nil)
+		ctx.Renderer.SetColorF32(1.0, 1.0, 1.0, 1.0)
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRibbonSideDistance(t *testing.T) {
+	// sharp turns, including some past the miter limit
+	pts := []PointF32{{10, 10}, {60, 10}, {60, 40}, {10, 44}, {70, 48}, {20, 90}}
+	widths := []float32{8, 8, 12, 8, 6, 8}
+
+	var r Renderer
+	vertices := make([]ebiten.Vertex, len(pts)*2)
+	r.setRibbonGeometry(0, 0, vertices, pts, widths)
+
+	// the side distance at each vertex must match its actual distance
+	// to the adjacent segments, so the antialiasing width is constant
+	for i, pt := range pts {
+		var segments [][2]PointF32
+		if i > 0 {
+			segments = append(segments, [2]PointF32{pts[i-1], pt})
+		}
+		if i+1 < len(pts) {
+			segments = append(segments, [2]PointF32{pt, pts[i+1]})
+		}
+		for j := range 2 {
+			v := vertices[i*2+j]
+			for _, seg := range segments {
+				dir := seg[1].Sub(seg[0]).Normalize()
+				dist := abs(dir.X*(v.DstY-pt.Y) - dir.Y*(v.DstX-pt.X))
+				if abs(dist-v.Custom1) > 1e-3 {
+					t.Errorf("point #%d: side distance %v, expected %v", i, v.Custom1, dist)
+				}
+			}
+		}
+	}
+}
+
+// go test -run ^TestDrawParametric$ . -count 1
+func TestDrawParametric(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.Black)
+		w, h := rectSizeF32(canvas.Bounds())
+		cx, cy := w/2.0, h/2.0
+		phase := ctx.ModAnim(2*math.Pi, 1.0)
+
+		// lissajous
+		ctx.Renderer.SetColorF32(1.0, 1.0, 1.0, 1.0)
+		lissajous := func(t float64) PointF32 {
+			return PointF32{X: cx + float32(160*math.Sin(3*t+phase)), Y: cy + float32(120*math.Sin(2*t))}
+		}
+		ctx.Renderer.DrawParametric(canvas, lissajous, 0, 2*math.Pi, 3.0)
+
+		// spiral
+		ctx.Renderer.SetColorF32(0.0, 0.5, 1.0, 1.0)
+		lx, ly := ctx.LeftClickF32()
+		spiral := func(t float64) PointF32 {
+			s, c := math.Sincos(t + phase)
+			return PointF32{X: lx + float32(t*4*c), Y: ly + float32(t*4*s)}
+		}
+		ctx.Renderer.DrawParametric(canvas, spiral, 0, 8*math.Pi, 1.5)
+
+		// sine wave
+		ctx.Renderer.SetColorF32(1.0, 0.5, 0.0, 1.0)
+		sine := func(t float64) PointF32 {
+			return PointF32{X: float32(t), Y: h - 64 + float32(32*math.Sin(t*0.05+phase))}
+		}
+		ctx.Renderer.DrawParametric(canvas, sine, 0, float64(w), 6.0)
+		ctx.Renderer.SetColorF32(1.0, 1.0, 1.0, 1.0)
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestDrawParametricSampling$ . -count 1
+func TestDrawParametricSampling(t *testing.T) {
+	curves := []struct {
+		name string
+		f    func(t float64) PointF32
+	}{
+		{"fast oscillation", func(t float64) PointF32 {
+			return PointF32{X: float32(t), Y: 50 + float32(40*math.Sin(t*1e4))}
+		}},
+		{"long spiral", func(t float64) PointF32 {
+			s, c := math.Sincos(t)
+			return PointF32{X: 50 + float32(t*c), Y: 50 + float32(t*s)}
+		}},
+		{"undefined start", func(t float64) PointF32 {
+			if t == 0 {
+				return PointF32{X: float32(math.NaN()), Y: float32(math.NaN())}
+			}
+			return PointF32{X: float32(t), Y: float32(math.Sqrt(t))}
+		}},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		target := ebiten.NewImage(100, 100)
+		for _, curve := range curves {
+			r.DrawParametric(target, curve.f, 0, 1000, 1)
+			if len(r.auxPoints) > MaxRibbonPoints {
+				fail("%s: %d samples", curve.name, len(r.auxPoints))
+			}
+			for _, pt := range r.auxPoints {
+				if isNaNPoint(pt) {
+					fail("%s: NaN sample", curve.name)
+					break
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,342 @@
+package shapes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SDF is an immutable signed distance field expression that can be drawn
+// with [Renderer.DrawSDF](). Expressions are built from primitives like
+// [SDFCircle]() and [SDFBox](), and combined with operators like [SDFUnion]()
+// or [SDFSubtraction]().
+//
+// Each distinct expression structure is compiled into a Kage shader the first
+// time it's drawn, and cached for the rest of the program's execution. The
+// parameters of the primitives and operators (positions, sizes, etc.) are
+// passed as uniforms, so expressions with the same structure but different
+// parameters share the same shader. In other words, animating a notch position
+// doesn't trigger any recompilation, but dynamically creating expressions of
+// arbitrary structures will slowly fill the cache.
+//
+// All coordinates are relative to the target's origin.
+type SDF struct {
+	kind     sdfKind
+	params   []float32
+	children []*SDF
+
+	// conservative bounds, without the antialiasing margin
+	minX, minY, maxX, maxY float32
+}
+
+type sdfKind uint8
+
+const (
+	sdfCircle sdfKind = iota
+	sdfBox
+	sdfSegment
+	sdfPolygon
+	sdfUnion
+	sdfIntersection
+	sdfSubtraction
+	sdfSmoothUnion
+	sdfRound
+	sdfOnion
+)
+
+// sdfShaders caches compiled SDF shaders by structure key, see [SDF.appendKey]().
+var sdfShaders map[string]*ebiten.Shader
+
+// SDFCircle creates a circle primitive.
+func SDFCircle(cx, cy, radius float32) *SDF {
+	return &SDF{
+		kind:   sdfCircle,
+		params: []float32{cx, cy, radius},
+		minX:   cx - radius, minY: cy - radius,
+		maxX: cx + radius, maxY: cy + radius,
+	}
+}
+
+// SDFBox creates an axis-aligned rectangle primitive. For rounded corners,
+// use [SDF.Round]() on a box shrunk by the rounding radius.
+func SDFBox(ox, oy, w, h float32) *SDF {
+	return &SDF{
+		kind:   sdfBox,
+		params: []float32{ox + w/2.0, oy + h/2.0, w / 2.0, h / 2.0},
+		minX:   ox, minY: oy,
+		maxX: ox + w, maxY: oy + h,
+	}
+}
+
+// SDFSegment creates a line segment primitive. Segments have no thickness
+// by themselves, so they are typically used with [SDF.Round]() to create
+// capsules.
+func SDFSegment(ox, oy, fx, fy float32) *SDF {
+	return &SDF{
+		kind:   sdfSegment,
+		params: []float32{ox, oy, fx, fy},
+		minX:   min(ox, fx), minY: min(oy, fy),
+		maxX: max(ox, fx), maxY: max(oy, fy),
+	}
+}
+
+// SDFPolygon creates a closed polygon primitive. The polygon can be concave,
+// but shouldn't self-intersect. The number of vertices is part of the expression
+// structure, so changing it requires a different shader.
+//
+// The function panics if less than 3 vertices are given.
+func SDFPolygon(vertices []PointF32) *SDF {
+	if len(vertices) < 3 {
+		panic("len(vertices) < 3")
+	}
+	sdf := &SDF{kind: sdfPolygon, params: make([]float32, 0, len(vertices)*2)}
+	sdf.minX, sdf.minY = vertices[0].X, vertices[0].Y
+	sdf.maxX, sdf.maxY = sdf.minX, sdf.minY
+	for _, v := range vertices {
+		sdf.params = append(sdf.params, v.X, v.Y)
+		sdf.minX, sdf.minY = min(sdf.minX, v.X), min(sdf.minY, v.Y)
+		sdf.maxX, sdf.maxY = max(sdf.maxX, v.X), max(sdf.maxY, v.Y)
+	}
+	return sdf
+}
+
+// SDFUnion combines the given expressions into a shape that covers all of them.
+// If only a is given, a is returned.
+func SDFUnion(a *SDF, others ...*SDF) *SDF {
+	sdf := a
+	for _, b := range others {
+		sdf = &SDF{
+			kind:     sdfUnion,
+			children: []*SDF{sdf, b},
+			minX:     min(sdf.minX, b.minX), minY: min(sdf.minY, b.minY),
+			maxX: max(sdf.maxX, b.maxX), maxY: max(sdf.maxY, b.maxY),
+		}
+	}
+	return sdf
+}
+
+// SDFIntersection creates a shape that only covers the areas where both a and b overlap.
+func SDFIntersection(a, b *SDF) *SDF {
+	return &SDF{
+		kind:     sdfIntersection,
+		children: []*SDF{a, b},
+		minX:     max(a.minX, b.minX), minY: max(a.minY, b.minY),
+		maxX: min(a.maxX, b.maxX), maxY: min(a.maxY, b.maxY),
+	}
+}
+
+// SDFSubtraction creates a shape that covers a, except where b is present.
+func SDFSubtraction(a, b *SDF) *SDF {
+	return &SDF{
+		kind:     sdfSubtraction,
+		children: []*SDF{a, b},
+		minX:     a.minX, minY: a.minY,
+		maxX: a.maxX, maxY: a.maxY,
+	}
+}
+
+// SDFSmoothUnion is like [SDFUnion](), but blends the shapes together where
+// their distance is below the given smoothness (in pixels). With smoothness <= 0,
+// the result is the same as a regular union.
+func SDFSmoothUnion(a, b *SDF, smoothness float32) *SDF {
+	smoothness = max(smoothness, 0)
+	margin := smoothness / 4.0 // max growth of the quadratic smooth min
+	return &SDF{
+		kind:     sdfSmoothUnion,
+		params:   []float32{smoothness},
+		children: []*SDF{a, b},
+		minX:     min(a.minX, b.minX) - margin, minY: min(a.minY, b.minY) - margin,
+		maxX: max(a.maxX, b.maxX) + margin, maxY: max(a.maxY, b.maxY) + margin,
+	}
+}
+
+// Round expands the shape by the given radius, rounding all its corners.
+func (s *SDF) Round(radius float32) *SDF {
+	radius = max(radius, 0)
+	return &SDF{
+		kind:     sdfRound,
+		params:   []float32{radius},
+		children: []*SDF{s},
+		minX:     s.minX - radius, minY: s.minY - radius,
+		maxX: s.maxX + radius, maxY: s.maxY + radius,
+	}
+}
+
+// Onion turns the shape into an outline of the given thickness, centered
+// on the original shape's boundary.
+func (s *SDF) Onion(thickness float32) *SDF {
+	halfThick := max(thickness, 0) / 2.0
+	return &SDF{
+		kind:     sdfOnion,
+		params:   []float32{halfThick},
+		children: []*SDF{s},
+		minX:     s.minX - halfThick, minY: s.minY - halfThick,
+		maxX: s.maxX + halfThick, maxY: s.maxY + halfThick,
+	}
+}
+
+// Bounds returns conservative bounds for the area covered by the shape,
+// not including the antialiasing margin. See [Renderer.SDFBounds]() for
+// the area that [Renderer.DrawSDF]() touches instead.
+func (s *SDF) Bounds() (minX, minY, maxX, maxY float32) {
+	return s.minX, s.minY, s.maxX, s.maxY
+}
+
+// drawRect returns the area drawn by [Renderer.DrawSDF](), including the
+// antialiasing margin, or false if the shape is empty.
+func (s *SDF) drawRect() (minX, minY, maxX, maxY float32, ok bool) {
+	const margin = 1.0 // antialiasing is applied inside the shape
+	if s.minX >= s.maxX || s.minY >= s.maxY {
+		return 0, 0, 0, 0, false
+	}
+	return s.minX - margin, s.minY - margin, s.maxX + margin, s.maxY + margin, true
+}
+
+// DrawSDF draws the given signed distance field expression with the renderer's
+// color. The first draw of each expression structure compiles a new shader, which
+// can take a noticeable amount of time, so consider drawing expressions once during
+// loading if this matters for your use-case.
+func (r *Renderer) DrawSDF(target *ebiten.Image, sdf *SDF) {
+	minX, minY, maxX, maxY, ok := sdf.drawRect()
+	if !ok {
+		return // nothing to draw
+	}
+
+	// only generate the source when the structure is not cached yet
+	r.sdfKey = sdf.appendKey(r.sdfKey[:0])
+	shader, found := sdfShaders[string(r.sdfKey)]
+	if !found {
+		if sdfShaders == nil {
+			sdfShaders = make(map[string]*ebiten.Shader)
+		}
+		var gen sdfCodegen
+		shader = mustCompile([]byte(gen.source(gen.emit(sdf))))
+		sdfShaders[string(r.sdfKey)] = shader
+	}
+
+	r.setLocalRectCoords(target, minX, minY, maxX, maxY)
+	r.opts.Uniforms["Params"] = sdf.appendParams(nil)
+	r.setSoftEdgeUniform()
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shader)
+	clear(r.opts.Uniforms)
+}
+
+// appendKey appends a key that identifies the expression structure, which
+// is all the generated shader source depends on: the node kinds, in pre-order,
+// and the number of vertices of polygons.
+func (s *SDF) appendKey(key []byte) []byte {
+	key = append(key, byte(s.kind))
+	if s.kind == sdfPolygon {
+		key = binary.AppendUvarint(key, uint64(len(s.params)/2))
+	}
+	for _, child := range s.children {
+		key = child.appendKey(key)
+	}
+	return key
+}
+
+// appendParams appends the parameters of the expression in the same
+// order as [sdfCodegen.emit](), which is the order of the Params uniform.
+func (s *SDF) appendParams(params []float32) []float32 {
+	for _, child := range s.children {
+		params = child.appendParams(params)
+	}
+	return append(params, s.params...)
+}
+
+// sdfCodegen generates the Kage source for an SDF expression, with one
+// function per node, and collects all the node parameters in a single
+// flat uniform array.
+type sdfCodegen struct {
+	funcs  strings.Builder
+	params []float32
+	nodes  int
+}
+
+// emit writes the function for the given node and its children, and
+// returns the node function's name.
+func (gen *sdfCodegen) emit(sdf *SDF) string {
+	var childFns [2]string
+	for i, child := range sdf.children {
+		childFns[i] = gen.emit(child)
+	}
+	name := fmt.Sprintf("sdf%d", gen.nodes)
+	gen.nodes += 1
+	base := len(gen.params)
+	gen.params = append(gen.params, sdf.params...)
+	param := func(offset int) string {
+		return fmt.Sprintf("Params[%d]", base+offset)
+	}
+
+	fmt.Fprintf(&gen.funcs, "\nfunc %s(p vec2) float {\n", name)
+	switch sdf.kind {
+	case sdfCircle:
+		fmt.Fprintf(&gen.funcs, "\treturn length(p - vec2(%s, %s)) - %s\n", param(0), param(1), param(2))
+	case sdfBox:
+		fmt.Fprintf(&gen.funcs, "\td := abs(p - vec2(%s, %s)) - vec2(%s, %s)\n", param(0), param(1), param(2), param(3))
+		fmt.Fprintf(&gen.funcs, "\treturn length(max(d, 0)) + min(max(d.x, d.y), 0)\n")
+	case sdfSegment:
+		fmt.Fprintf(&gen.funcs, "\ta := vec2(%s, %s)\n", param(0), param(1))
+		fmt.Fprintf(&gen.funcs, "\tba := vec2(%s, %s) - a\n", param(2), param(3))
+		fmt.Fprintf(&gen.funcs, "\tpa := p - a\n")
+		fmt.Fprintf(&gen.funcs, "\th := clamp(dot(pa, ba)/max(dot(ba, ba), 0.000001), 0, 1)\n")
+		fmt.Fprintf(&gen.funcs, "\treturn length(pa - ba*h)\n")
+	case sdfPolygon:
+		n := len(sdf.params) / 2
+		fmt.Fprintf(&gen.funcs, "\tv0 := vec2(%s, %s)\n", param(0), param(1))
+		fmt.Fprintf(&gen.funcs, "\td := dot(p - v0, p - v0)\n")
+		fmt.Fprintf(&gen.funcs, "\ts := 1.0\n")
+		fmt.Fprintf(&gen.funcs, "\tfor i := 0; i < %d; i++ {\n", n)
+		fmt.Fprintf(&gen.funcs, "\t\tj := i - 1\n")
+		fmt.Fprintf(&gen.funcs, "\t\tif j < 0 {\n\t\t\tj = %d\n\t\t}\n", n-1)
+		fmt.Fprintf(&gen.funcs, "\t\tvi := vec2(Params[%d+i*2], Params[%d+i*2])\n", base, base+1)
+		fmt.Fprintf(&gen.funcs, "\t\tvj := vec2(Params[%d+j*2], Params[%d+j*2])\n", base, base+1)
+		fmt.Fprintf(&gen.funcs, "\t\te := vj - vi\n")
+		fmt.Fprintf(&gen.funcs, "\t\tw := p - vi\n")
+		fmt.Fprintf(&gen.funcs, "\t\tb := w - e*clamp(dot(w, e)/max(dot(e, e), 0.000001), 0, 1)\n")
+		fmt.Fprintf(&gen.funcs, "\t\td = min(d, dot(b, b))\n")
+		fmt.Fprintf(&gen.funcs, "\t\tc1, c2, c3 := p.y >= vi.y, p.y < vj.y, e.x*w.y > e.y*w.x\n")
+		fmt.Fprintf(&gen.funcs, "\t\tif (c1 && c2 && c3) || (!c1 && !c2 && !c3) {\n\t\t\ts = -s\n\t\t}\n")
+		fmt.Fprintf(&gen.funcs, "\t}\n")
+		fmt.Fprintf(&gen.funcs, "\treturn s * sqrt(d)\n")
+	case sdfUnion:
+		fmt.Fprintf(&gen.funcs, "\treturn min(%s(p), %s(p))\n", childFns[0], childFns[1])
+	case sdfIntersection:
+		fmt.Fprintf(&gen.funcs, "\treturn max(%s(p), %s(p))\n", childFns[0], childFns[1])
+	case sdfSubtraction:
+		fmt.Fprintf(&gen.funcs, "\treturn max(%s(p), -%s(p))\n", childFns[0], childFns[1])
+	case sdfSmoothUnion:
+		fmt.Fprintf(&gen.funcs, "\ta, b, k := %s(p), %s(p), %s\n", childFns[0], childFns[1], param(0))
+		fmt.Fprintf(&gen.funcs, "\tif k <= 0 {\n\t\treturn min(a, b)\n\t}\n")
+		fmt.Fprintf(&gen.funcs, "\th := max(k - abs(a - b), 0) / k\n")
+		fmt.Fprintf(&gen.funcs, "\treturn min(a, b) - h*h*k*0.25\n")
+	case sdfRound:
+		fmt.Fprintf(&gen.funcs, "\treturn %s(p) - %s\n", childFns[0], param(0))
+	case sdfOnion:
+		fmt.Fprintf(&gen.funcs, "\treturn abs(%s(p)) - %s\n", childFns[0], param(0))
+	default:
+		panic("unexpected SDF kind")
+	}
+	fmt.Fprintf(&gen.funcs, "}\n")
+	return name
+}
+
+// source returns the full Kage shader source, with the given root function.
+func (gen *sdfCodegen) source(rootFn string) string {
+	var src strings.Builder
+	src.WriteString("//kage:unit pixels\npackage main\n\n")
+	fmt.Fprintf(&src, "var Params [%d]float\n", len(gen.params))
+	src.WriteString("var SoftEdge float\n")
+	src.WriteString(`
+func Fragment(_ vec4, sourceCoords vec2, color vec4) vec4 {
+	dist := ` + rootFn + `(sourceCoords)
+	alpha := 1.0 - smoothstep(-SoftEdge, 0, dist)
+	alpha = pow(alpha, 1.0/2.2)
+	return color * alpha
+}
+`)
+	src.WriteString(gen.funcs.String())
+	return src.String()
+}
@@ -0,0 +1,89 @@
+package shapes
+
+import (
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// go test -run ^TestSDFCodegenSharing$ . -count 1
+func TestSDFCodegenSharing(t *testing.T) {
+	makeSDF := func(notchX, notchRadius float32) *SDF {
+		box := SDFBox(16, 16, 128, 64).Round(8)
+		return SDFSubtraction(box, SDFCircle(notchX, 8, notchRadius))
+	}
+
+	var genA, genB, genC sdfCodegen
+	srcA := genA.source(genA.emit(makeSDF(64, 12)))
+	srcB := genB.source(genB.emit(makeSDF(96, 16)))
+	if srcA != srcB {
+		t.Fatalf("expected same source for same structure\nA:\n%s\nB:\n%s", srcA, srcB)
+	}
+	if slices.Equal(genA.params, genB.params) {
+		t.Fatalf("expected different params, got %v", genA.params)
+	}
+	expectedParams := []float32{80, 48, 64, 32, 8, 64, 8, 12}
+	if !slices.Equal(genA.params, expectedParams) {
+		t.Fatalf("expected params %v, got %v", expectedParams, genA.params)
+	}
+
+	srcC := genC.source(genC.emit(SDFUnion(makeSDF(64, 12), SDFCircle(0, 0, 4))))
+	if srcA == srcC {
+		t.Fatalf("expected different source for different structure")
+	}
+
+	// the cache key and the params must match the codegen without running it
+	if string(makeSDF(64, 12).appendKey(nil)) != string(makeSDF(96, 16).appendKey(nil)) {
+		t.Fatalf("expected same key for same structure")
+	}
+	if params := makeSDF(64, 12).appendParams(nil); !slices.Equal(params, genA.params) {
+		t.Fatalf("expected params %v, got %v", genA.params, params)
+	}
+	triangle := []PointF32{{0, 0}, {8, 0}, {0, 8}}
+	quad := []PointF32{{0, 0}, {8, 0}, {8, 8}, {0, 8}}
+	keys := map[string]bool{}
+	for _, sdf := range []*SDF{
+		makeSDF(64, 12),
+		SDFUnion(makeSDF(64, 12), SDFCircle(0, 0, 4)),
+		SDFUnion(SDFCircle(0, 0, 4), makeSDF(64, 12)),
+		SDFPolygon(triangle),
+		SDFPolygon(quad),
+		SDFPolygon(quad).Onion(2),
+	} {
+		keys[string(sdf.appendKey(nil))] = true
+	}
+	if len(keys) != 6 {
+		t.Fatalf("expected different keys for different structures")
+	}
+}
+
+// go test -run ^TestDrawSDF$ . -count 1
+func TestDrawSDF(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.RGBA{32, 32, 32, 255})
+		lx, ly := ctx.LeftClickF32()
+		rx, ry := ctx.RightClickF32()
+
+		// rounded rect with a notch following the left click
+		card := SDFBox(lx-120, ly-60, 240, 120).Round(12)
+		notch := SDFCircle(lx+float32(ctx.DistAnim(96.0, 1.0)), ly-72, 24)
+		glyph := SDFSubtraction(card, notch)
+
+		// blob made of a capsule and a triangle, with an outlined ring
+		capsule := SDFSegment(rx-64, ry+64, rx+64, ry-64).Round(10)
+		triangle := SDFPolygon([]PointF32{{rx - 48, ry - 48}, {rx + 8, ry - 64}, {rx - 24, ry}})
+		blob := SDFSmoothUnion(capsule, triangle, 24)
+		ring := SDFCircle(rx, ry, 80).Onion(4)
+
+		ctx.Renderer.SetColor(color.RGBA{0, 196, 255, 255})
+		ctx.Renderer.DrawSDF(canvas, glyph)
+		ctx.Renderer.SetColor(color.RGBA{255, 128, 0, 255})
+		ctx.Renderer.DrawSDF(canvas, SDFUnion(blob, ring))
+		ctx.Renderer.SetColor(color.RGBA{255, 255, 255, 255})
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
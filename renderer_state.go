@@ -0,0 +1,72 @@
+package shapes
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// rendererState is a snapshot of the renderer's drawing state, see [Renderer.Push]().
+type rendererState struct {
+	vertices       [4]ebiten.Vertex // for colors and custom VAs
+	singleClr      bool
+	legacyRounding bool
+	pixelPerfect   bool
+	softEdge       float32
+	sourceClamping Clamping
+	blend          ebiten.Blend
+	geoM           ebiten.GeoM
+	clipRect       image.Rectangle
+	hasClip        bool
+}
+
+// Push saves the current drawing state, which can be restored later with [Renderer.Pop]().
+// The state includes the color and vertex colors, custom VAs, blend, soft edge, pixel-perfect
+// mode, legacy rounding, source clamping, GeoM and clip rect. This allows helper functions to
+// change the state without leaking it to their callers:
+//
+//	func drawBadge(r *shapes.Renderer, target *ebiten.Image, x, y float32) {
+//		r.Push()
+//		defer r.Pop()
+//		r.SetColor(badgeColor)
+//		r.SetBlend(ebiten.BlendLighter)
+//		r.DrawCircle(target, x, y, 6)
+//	}
+//
+// Push and Pop calls must be balanced. Uniforms and images set manually through
+// [Renderer.Options]() are not part of the state.
+func (r *Renderer) Push() {
+	state := rendererState{
+		singleClr:      r.singleClr,
+		legacyRounding: r.legacyRounding,
+		pixelPerfect:   r.pixelPerfect,
+		softEdge:       r.softEdge,
+		sourceClamping: r.sourceClamping,
+		blend:          r.opts.Blend,
+		geoM:           r.geoM,
+		clipRect:       r.clipRect,
+		hasClip:        r.hasClip,
+	}
+	copy(state.vertices[:], r.vertices)
+	r.stateStack = append(r.stateStack, state)
+}
+
+// Pop restores the drawing state saved by the last [Renderer.Push]() call.
+// The function panics if there's no matching Push() call.
+func (r *Renderer) Pop() {
+	if len(r.stateStack) == 0 {
+		panic("Pop() without matching Push()")
+	}
+	state := r.stateStack[len(r.stateStack)-1]
+	r.stateStack = r.stateStack[:len(r.stateStack)-1]
+
+	copy(r.vertices, state.vertices[:])
+	r.singleClr = state.singleClr
+	r.legacyRounding = state.legacyRounding
+	r.pixelPerfect = state.pixelPerfect
+	r.softEdge = state.softEdge
+	r.sourceClamping = state.sourceClamping
+	r.opts.Blend = state.blend
+	r.SetGeoM(state.geoM)
+	r.clipRect, r.hasClip = state.clipRect, state.hasClip
+}
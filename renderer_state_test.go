@@ -0,0 +1,59 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestPushPop(t *testing.T) {
+	r := NewRenderer()
+	r.SetColor(color.RGBA{255, 0, 0, 255})
+	r.SetCustomVAs(1, 2, 3, 4)
+	r.SetBlend(ebiten.BlendSourceOver)
+
+	r.Push()
+	r.SetColor(color.RGBA{0, 255, 0, 255}, 1, 2)
+	r.SetCustomVAs(5, 6)
+	r.SetBlend(ebiten.BlendLighter)
+	r.SetSoftEdge(3)
+	r.SetPixelPerfect(true)
+	r.SetSourceClamping(ClampAll)
+	var geoM ebiten.GeoM
+	geoM.Scale(2, 2)
+	r.SetGeoM(geoM)
+	r.SetClipRect(image.Rect(0, 0, 32, 32))
+
+	r.Push()
+	r.SetColor(color.White)
+	r.Pop()
+	if r.singleClr || r.vertices[1].ColorG != 1 || r.vertices[0].ColorR != 1 {
+		t.Error("nested Pop() didn't restore vertex colors")
+	}
+
+	r.Pop()
+	if r.GetColorF32() != [4]float32{1, 0, 0, 1} || !r.singleClr || r.vertices[2].ColorG != 0 {
+		t.Error("Pop() didn't restore the color")
+	}
+	if v := r.vertices[3]; v.Custom0 != 1 || v.Custom1 != 2 || v.Custom2 != 3 || v.Custom3 != 4 {
+		t.Error("Pop() didn't restore custom VAs")
+	}
+	if r.opts.Blend != ebiten.BlendSourceOver || r.softEdge != AAMargin || r.pixelPerfect || r.sourceClamping != ClampNone {
+		t.Error("Pop() didn't restore the blend and modes")
+	}
+	if r.GetGeoM() != (ebiten.GeoM{}) || r.transforming() {
+		t.Error("Pop() didn't restore the GeoM")
+	}
+	if _, hasClip := r.GetClipRect(); hasClip || r.clipping() {
+		t.Error("Pop() didn't restore the clip rect")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for unbalanced Pop()")
+		}
+	}()
+	r.Pop()
+}
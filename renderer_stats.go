@@ -0,0 +1,183 @@
+package shapes
+
+import (
+	"maps"
+	"reflect"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Stats reports the draws and offscreens used by a renderer since the
+// last [Renderer.ResetStats]() call. See [Renderer.Stats]().
+type Stats struct {
+	// DrawCalls is the number of DrawTrianglesShader and DrawImage
+	// calls made by the renderer.
+	DrawCalls int
+
+	// ShaderSwitches is the number of draws that used a different
+	// shader than the previous draw.
+	ShaderSwitches int
+
+	// UniformChanges is the number of draws that used the same shader
+	// as the previous draw, but with different uniform values.
+	UniformChanges int
+
+	// Batches is an estimation of the number of batches Ebitengine will
+	// need for the renderer draws. Consecutive draws can only be merged
+	// if they share target, shader, images, blend and uniforms. Draws
+	// made outside the renderer are not taken into account.
+	Batches int
+
+	// ClippedDraws is the number of draws skipped because they were
+	// fully outside the clip rect. See [Renderer.SetClipRect]().
+	ClippedDraws int
+
+	// OffscreenAllocs is the number of internal offscreens created.
+	OffscreenAllocs int
+
+	// OffscreenReallocs is the number of internal offscreens replaced
+	// by bigger ones because they couldn't fit a requested size.
+	OffscreenReallocs int
+
+	// OffscreenMemory is the memory currently used by the offscreens
+	// in the renderer's [OffscreenPool], in bytes, including leases.
+	// Unlike the other fields, it's not reset by [Renderer.ResetStats]().
+	OffscreenMemory int
+}
+
+// Stats returns the renderer statistics since the last [Renderer.ResetStats]() call.
+// This is mostly useful to find out why a scene is breaking batching or allocating
+// too many offscreens. To get per frame statistics, reset the stats at the start of
+// each frame, and check them at the end:
+//
+//	func (game *Game) Draw(screen *ebiten.Image) {
+//		game.renderer.ResetStats()
+//		// ... draw the frame ...
+//		stats := game.renderer.Stats()
+//		ebitenutil.DebugPrint(screen, fmt.Sprintf("draws: %d, batches: %d", stats.DrawCalls, stats.Batches))
+//	}
+func (r *Renderer) Stats() Stats {
+	stats := r.stats.stats
+	stats.OffscreenMemory = r.pool.memory
+	return stats
+}
+
+// ResetStats resets the renderer statistics. See [Renderer.Stats]().
+func (r *Renderer) ResetStats() {
+	r.stats.stats = Stats{}
+	r.stats.hasLastDraw = false
+}
+
+// drawTrianglesShader draws the triangles with the renderer's options
+// while tracking stats. All the renderer draws must go through this
+// function or [Renderer.drawImage](). The renderer's GeoM and clip rect
+// are applied here.
+func (r *Renderer) drawTrianglesShader(target *ebiten.Image, vertices []ebiten.Vertex, indices []uint16, shader *ebiten.Shader) {
+	if r.transforming() {
+		vertices = r.transformVertices(target, vertices)
+	}
+	if r.clipping() {
+		r.drawClipped(target, vertices, indices, shader)
+	} else {
+		r.submitTriangles(target, vertices, indices, shader)
+	}
+}
+
+// submitTriangles draws the final triangles and tracks the draw.
+func (r *Renderer) submitTriangles(target *ebiten.Image, vertices []ebiten.Vertex, indices []uint16, shader *ebiten.Shader) {
+	r.stats.trackDraw(target, shader, r.opts.Images, r.opts.Blend, r.opts.Uniforms)
+	target.DrawTrianglesShader(vertices, indices, shader, &r.opts)
+}
+
+// drawImage is the DrawImage equivalent of [Renderer.drawTrianglesShader](),
+// only used for internal copies, so the GeoM and clip rect are not applied.
+func (r *Renderer) drawImage(target, source *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	r.stats.trackDraw(target, nil, [4]*ebiten.Image{source}, opts.Blend, nil)
+	target.DrawImage(source, opts)
+}
+
+// statsTracker keeps the [Stats] and the state of the last draw,
+// which is needed to detect the draws that break batching.
+type statsTracker struct {
+	stats Stats
+
+	hasLastDraw  bool
+	lastTarget   *ebiten.Image
+	lastShader   *ebiten.Shader
+	lastImages   [4]*ebiten.Image
+	lastBlend    ebiten.Blend
+	lastUniforms map[string]any
+}
+
+func (t *statsTracker) trackDraw(target *ebiten.Image, shader *ebiten.Shader, images [4]*ebiten.Image, blend ebiten.Blend, uniforms map[string]any) {
+	t.stats.DrawCalls += 1
+	if !t.hasLastDraw {
+		t.stats.Batches += 1
+		t.setLastDraw(target, shader, images, blend, uniforms)
+		return
+	}
+
+	shaderSwitch := (shader != t.lastShader)
+	uniformChange := !shaderSwitch && !uniformsEqual(uniforms, t.lastUniforms)
+	if shaderSwitch {
+		t.stats.ShaderSwitches += 1
+	}
+	if uniformChange {
+		t.stats.UniformChanges += 1
+	}
+	if shaderSwitch || uniformChange || target != t.lastTarget || images != t.lastImages || blend != t.lastBlend {
+		t.stats.Batches += 1
+		t.setLastDraw(target, shader, images, blend, uniforms)
+	}
+}
+
+func (t *statsTracker) setLastDraw(target *ebiten.Image, shader *ebiten.Shader, images [4]*ebiten.Image, blend ebiten.Blend, uniforms map[string]any) {
+	t.hasLastDraw = true
+	t.lastTarget, t.lastShader = target, shader
+	t.lastImages, t.lastBlend = images, blend
+	if t.lastUniforms == nil {
+		t.lastUniforms = make(map[string]any, len(uniforms))
+	}
+	clear(t.lastUniforms)
+	maps.Copy(t.lastUniforms, uniforms)
+}
+
+// trackOffscreen updates the stats after requesting an offscreen
+// that was backed by prevParent and is now backed by newParent.
+func (t *statsTracker) trackOffscreen(prevParent, newParent *ebiten.Image) {
+	if newParent == prevParent {
+		return
+	}
+	if prevParent == nil {
+		t.stats.OffscreenAllocs += 1
+	} else {
+		t.stats.OffscreenReallocs += 1
+	}
+}
+
+// imageMemory returns the memory used by the image pixels, in bytes.
+func imageMemory(img *ebiten.Image) int {
+	bounds := img.Bounds()
+	return bounds.Dx() * bounds.Dy() * 4
+}
+
+// uniformsEqual returns whether two uniform maps have the same values.
+func uniformsEqual(a, b map[string]any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		other, found := b[key]
+		if !found {
+			return false
+		}
+		if f32, isF32 := value.(float32); isF32 {
+			if otherF32, isF32 := other.(float32); !isF32 || f32 != otherF32 {
+				return false
+			}
+		} else if !reflect.DeepEqual(value, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package shapes
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// go test -run ^TestStats$ . -count 1
+func TestStats(t *testing.T) {
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		expect := func(name string, got, want Stats) {
+			if got != want {
+				fail("%s: got %+v, want %+v", name, got, want)
+			}
+		}
+
+		r := NewRenderer()
+		target := ebiten.NewImage(64, 64)
+
+		// same shader and uniforms, a single batch
+		for i := range 3 {
+			r.DrawCircle(target, 10+float32(i)*10, 10, 4)
+		}
+		expect("circles", r.Stats(), Stats{DrawCalls: 3, Batches: 1})
+
+		// different uniforms, then a shader switch
+		r.ResetStats()
+		r.DrawRingSector(target, 32, 32, 8, 16, 0, math.Pi, 0)
+		r.DrawRingSector(target, 32, 32, 8, 16, 0, math.Pi, 2)
+		r.DrawCircle(target, 10, 10, 4)
+		expect("sectors", r.Stats(), Stats{DrawCalls: 3, ShaderSwitches: 1, UniformChanges: 1, Batches: 3})
+
+		// effect uniforms must not leak into the following draws
+		mask := ebiten.NewImage(16, 16)
+		r.ResetStats()
+		r.ApplyBlur(target, mask, 0, 0, 4, 0)
+		r.DrawCircle(target, 10, 10, 4)
+		r.DrawCircle(target, 20, 10, 4)
+		expect("effect", r.Stats(), Stats{DrawCalls: 3, ShaderSwitches: 1, Batches: 2})
+
+		// offscreens, with a 64px extra margin
+		r.ResetStats()
+		r.UnsafeTemp(0, 32, 32)
+		r.UnsafeTemp(0, 16, 16)
+		expect("alloc", r.Stats(), Stats{OffscreenAllocs: 1, OffscreenMemory: 96 * 96 * 4})
+		r.UnsafeTemp(0, 200, 10)
+		expect("realloc", r.Stats(), Stats{OffscreenAllocs: 1, OffscreenReallocs: 1, OffscreenMemory: 264 * 96 * 4})
+		r.ResetStats()
+		expect("reset", r.Stats(), Stats{OffscreenMemory: 264 * 96 * 4})
+	})
+}
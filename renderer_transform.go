@@ -0,0 +1,135 @@
+package shapes
+
+import (
+	"image"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SetGeoM sets a transform that's applied to all the renderer draws, including shapes,
+// batches, masks and effects. Coordinates passed to the renderer are transformed by the
+// GeoM, and the result is relative to the target's origin as usual. This is useful for
+// cameras, minimaps and any other world space drawing:
+//
+//	var camera ebiten.GeoM
+//	camera.Translate(-cam.X, -cam.Y)
+//	camera.Rotate(cam.Rads)
+//	camera.Scale(cam.Zoom, cam.Zoom)
+//	camera.Translate(screenWidth/2, screenHeight/2)
+//	renderer.SetGeoM(camera)
+//	renderer.DrawCircle(screen, enemy.X, enemy.Y, enemy.Radius) // world coordinates
+//	renderer.ResetGeoM()
+//
+// The GeoM is part of the state saved by [Renderer.Push](), so Push and Pop can be
+// used as a transform stack.
+//
+// Shapes are still computed with their exact SDFs under rotation and scaling, and the
+// soft edge set with [Renderer.SetSoftEdge]() (or passed to [Renderer.DrawQuadSoft]()
+// and [Renderer.DrawMetaballs]()) is kept in target pixels. Non-uniform scaling uses
+// the average scale factor, so edges along the axes scaled the most and the least
+// are slightly softer and sharper, respectively. Everything else, including stroke
+// thicknesses, effect radiuses and margins, is given in local units and scales
+// with the transform. Some other details to consider:
+//   - Bounds and hit test functions keep working in local coordinates.
+//   - Pixel-perfect snapping is also done in local coordinates, so it only makes
+//     sense with integer translations.
+//   - Effects sample their sources without extra filtering, so they can look
+//     pixelated when scaled up or rotated.
+//   - [Renderer.DitherMat4]() and [Renderer.HalftoneTri]() patterns stay aligned
+//     to the target pixels.
+//   - Internal offscreens are drawn without the transform, so the results of
+//     multi-pass effects are transformed as a whole.
+func (r *Renderer) SetGeoM(geoM ebiten.GeoM) {
+	r.geoM = geoM
+	r.hasGeoM = (geoM != ebiten.GeoM{})
+	if r.hasGeoM {
+		r.geoMElems = [6]float32{
+			float32(geoM.Element(0, 0)), float32(geoM.Element(0, 1)), float32(geoM.Element(0, 2)),
+			float32(geoM.Element(1, 0)), float32(geoM.Element(1, 1)), float32(geoM.Element(1, 2)),
+		}
+		r.geoMScale = max(float32(math.Sqrt(math.Abs(geoM.Element(0, 0)*geoM.Element(1, 1)-geoM.Element(0, 1)*geoM.Element(1, 0)))), 1e-6)
+	}
+}
+
+// GetGeoM returns the transform set with [Renderer.SetGeoM]().
+func (r *Renderer) GetGeoM() ebiten.GeoM {
+	return r.geoM
+}
+
+// ResetGeoM resets the renderer's transform to the identity. See [Renderer.SetGeoM]().
+func (r *Renderer) ResetGeoM() {
+	r.SetGeoM(ebiten.GeoM{})
+}
+
+// transforming returns whether the next draw will be transformed by the GeoM.
+func (r *Renderer) transforming() bool {
+	return r.hasGeoM && r.internalPasses == 0
+}
+
+// localSoftEdge converts a soft edge in target pixels to local units.
+func (r *Renderer) localSoftEdge(softEdge float32) float32 {
+	if !r.transforming() {
+		return softEdge
+	}
+	return softEdge / r.geoMScale
+}
+
+// localTargetBounds returns the bounds of the target, relative to its
+// origin, in local coordinates. If the GeoM is not the identity, this
+// is the bounding box of the transformed target rect.
+func (r *Renderer) localTargetBounds(target *ebiten.Image) (minX, minY, maxX, maxY float32) {
+	w, h := rectSizeF32(target.Bounds())
+	if !r.transforming() {
+		return 0, 0, w, h
+	}
+	if !r.geoM.IsInvertible() {
+		return 0, 0, 0, 0
+	}
+	inverse := r.geoM
+	inverse.Invert()
+	minX, minY = float32(math.Inf(1)), float32(math.Inf(1))
+	maxX, maxY = float32(math.Inf(-1)), float32(math.Inf(-1))
+	for _, corner := range rectCorners(0, 0, w, h) {
+		x, y := inverse.Apply(float64(corner.X), float64(corner.Y))
+		minX, minY = min(minX, float32(x)), min(minY, float32(y))
+		maxX, maxY = max(maxX, float32(x)), max(maxY, float32(y))
+	}
+	return minX, minY, maxX, maxY
+}
+
+// transformVertices returns a copy of the given vertices with the
+// destination coordinates transformed by the GeoM around the target's
+// origin. The copy is only valid until the next call.
+func (r *Renderer) transformVertices(target *ebiten.Image, vertices []ebiten.Vertex) []ebiten.Vertex {
+	dstOX, dstOY := rectOriginF32(target.Bounds())
+	r.geoMVertices = slices.Grow(r.geoMVertices[:0], len(vertices))[:len(vertices)]
+	m := &r.geoMElems
+	for i, vertex := range vertices {
+		x, y := vertex.DstX-dstOX, vertex.DstY-dstOY
+		vertex.DstX = dstOX + m[0]*x + m[1]*y + m[2]
+		vertex.DstY = dstOY + m[3]*x + m[4]*y + m[5]
+		r.geoMVertices[i] = vertex
+	}
+	return r.geoMVertices
+}
+
+// targetRect returns the rect of the target, relative to its origin, that
+// covers the given local rect after applying the GeoM, rounded outwards.
+func (r *Renderer) targetRect(minX, minY, maxX, maxY float32) image.Rectangle {
+	if r.transforming() {
+		corners := rectCorners(minX, minY, maxX, maxY)
+		minX, minY = float32(math.Inf(1)), float32(math.Inf(1))
+		maxX, maxY = float32(math.Inf(-1)), float32(math.Inf(-1))
+		for _, corner := range corners {
+			x, y := r.geoM.Apply(float64(corner.X), float64(corner.Y))
+			minX, minY = min(minX, float32(x)), min(minY, float32(y))
+			maxX, maxY = max(maxX, float32(x)), max(maxY, float32(y))
+		}
+	}
+	return image.Rect(
+		int(math.Floor(float64(minX))), int(math.Floor(float64(minY))),
+		int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY))),
+	)
+}
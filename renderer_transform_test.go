@@ -0,0 +1,192 @@
+package shapes
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// go test -run ^TestGeoM$ . -count 1
+func TestGeoM(t *testing.T) {
+	app := NewTestApp(func(canvas *ebiten.Image, ctx TestAppCtx) {
+		canvas.Fill(color.RGBA{16, 16, 24, 255})
+		bounds := canvas.Bounds()
+		lx, ly := ctx.LeftClickF64()
+
+		// a small world seen through a rotating and zooming camera
+		// centered on the last left click
+		var camera ebiten.GeoM
+		camera.Translate(-200, -150)
+		camera.Rotate(ctx.RadsAnim(0.5))
+		zoom := 0.5 + ctx.DistAnim(3.5, 0.3)
+		camera.Scale(zoom, zoom)
+		camera.Translate(lx, ly)
+		ctx.Renderer.SetGeoM(camera)
+
+		ctx.Renderer.SetColor(color.RGBA{40, 40, 60, 255})
+		ctx.Renderer.DrawArea(canvas, 0, 0, 400, 300, -24)
+		ctx.Renderer.SetColor(color.RGBA{200, 120, 40, 255})
+		ctx.Renderer.DrawRing(canvas, 100, 100, 30, 40)
+		ctx.Renderer.SetColor(color.RGBA{80, 200, 255, 255})
+		ctx.Renderer.DrawArea(canvas, 220, 60, 120, 60, -12)
+		ctx.Renderer.SetColor(color.RGBA{240, 240, 240, 255})
+		ctx.Renderer.DrawLine(canvas, 40, 260, 360, 180, 2)
+		ctx.Renderer.DrawPie(canvas, 300, 220, 50, 0.3, 2.2, -4)
+		ctx.Renderer.DrawTriangle(canvas, 60, 160, 120, 240, 20, 230, -4)
+		ctx.Renderer.ResetGeoM()
+
+		// minimap, drawn with the same world coordinates
+		var minimap ebiten.GeoM
+		minimap.Scale(0.25, 0.25)
+		minimap.Translate(float64(bounds.Dx())-110, 10)
+		ctx.Renderer.Push()
+		ctx.Renderer.SetGeoM(minimap)
+		ctx.Renderer.SetColor(color.RGBA{40, 40, 60, 255})
+		ctx.Renderer.DrawArea(canvas, 0, 0, 400, 300, -24)
+		ctx.Renderer.SetColor(color.RGBA{200, 120, 40, 255})
+		ctx.Renderer.DrawRing(canvas, 100, 100, 30, 40)
+		ctx.Renderer.SetColor(color.RGBA{80, 200, 255, 255})
+		ctx.Renderer.DrawArea(canvas, 220, 60, 120, 60, -12)
+		ctx.Renderer.Pop()
+	})
+	if err := ebiten.RunGame(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -run ^TestGeoMMatchesDraws$ . -count 1
+func TestGeoMMatchesDraws(t *testing.T) {
+	const w, h = 96, 72
+	const offX, offY = 37, 23
+
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	quad := [4]PointF32{{X: 14, Y: 10}, {X: 80, Y: 18}, {X: 70, Y: 64}, {X: 22, Y: 56}}
+
+	// translated draws must match draws on an offset subimage,
+	// which also checks that internal offscreens aren't transformed
+	var mask, source *ebiten.Image
+	cases := []struct {
+		name string
+		draw func(r *Renderer, target *ebiten.Image)
+	}{
+		{"DrawArea", func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, 10, 8, 40, 30, 6) }},
+		{"StrokeArea", func(r *Renderer, target *ebiten.Image) { r.StrokeArea(target, 12, 10, 50, 40, 2, 2, 4) }},
+		{"DrawTaperedLine", func(r *Renderer, target *ebiten.Image) {
+			r.DrawTaperedLine(target, 8, 8, 80, 60, 2, 8, color.White, red)
+		}},
+		{"DrawCircle", func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, 48, 36, 20) }},
+		{"StrokeCircle", func(r *Renderer, target *ebiten.Image) { r.StrokeCircle(target, 48, 36, 20, 4) }},
+		{"DrawRing", func(r *Renderer, target *ebiten.Image) { r.DrawRing(target, 48, 36, 12, 24) }},
+		{"DrawRingSector", func(r *Renderer, target *ebiten.Image) {
+			r.DrawRingSector(target, 48, 36, 12, 30, 0.3, 2.5, 0)
+		}},
+		{"StrokePie", func(r *Renderer, target *ebiten.Image) { r.StrokePie(target, 48, 36, 28, 3, 0.5, 2.8, 0) }},
+		{"DrawEllipse", func(r *Renderer, target *ebiten.Image) { r.DrawEllipse(target, 48, 36, 30, 16, 0.4) }},
+		{"DrawQuad", func(r *Renderer, target *ebiten.Image) { r.DrawQuad(target, quad, 2) }},
+		{"DrawMetaballs", func(r *Renderer, target *ebiten.Image) {
+			r.DrawMetaballs(target, []PointF32{{X: 36, Y: 36}, {X: 60, Y: 36}}, []float32{14, 12}, 1.0, AAMargin)
+		}},
+		{"DrawSDF", func(r *Renderer, target *ebiten.Image) {
+			r.DrawSDF(target, SDFSmoothUnion(SDFCircle(36, 36, 18), SDFBox(48, 20, 36, 30), 8))
+		}},
+		{"TileDotsGrid", func(r *Renderer, target *ebiten.Image) { r.TileDotsGrid(target, 3, 10, 2, 1) }},
+		{"ApplyExpansionRect", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyExpansionRect(target, mask, 20, 10, 4)
+		}},
+		{"ApplyBlur2", func(r *Renderer, target *ebiten.Image) { r.ApplyBlur2(target, mask, 20, 10, 8, 1) }},
+		{"ApplyGlow", func(r *Renderer, target *ebiten.Image) { r.ApplyGlow(target, mask, 20, 10, 6, 6, 0.1, 0.9, 0.5) }},
+		{"ApplyBlurD4", func(r *Renderer, target *ebiten.Image) {
+			r.ApplyBlurD4(target, mask, 20, 10, GaussKern5, GaussKern5, 1)
+		}},
+		{"JFMExpand", func(r *Renderer, target *ebiten.Image) { r.JFMExpand(target, mask, nil, 20, 10, 4, AAMargin) }},
+		{"Gradient", func(r *Renderer, target *ebiten.Image) {
+			r.Gradient(target, mask, 20, 10, red, blue, 8, 0.5, 1)
+		}},
+		{"GradientRadial", func(r *Renderer, target *ebiten.Image) {
+			r.GradientRadial(target, 48, 36, red, blue, 4, 12, 30, 8, 1)
+		}},
+		{"MaskHorz", func(r *Renderer, target *ebiten.Image) { r.MaskHorz(target, source, 20, 10, 30, 44) }},
+		{"MaskCircle", func(r *Renderer, target *ebiten.Image) { r.MaskCircle(target, source, 48, 36, 0, 0, 14, 4) }},
+		{"MapProjective", func(r *Renderer, target *ebiten.Image) { r.MapProjective(target, source, quad) }},
+		{"WarpArc", func(r *Renderer, target *ebiten.Image) { r.WarpArc(target, source, 48, 70, 56, RadsTop) }},
+	}
+
+	// rotations and scales must match the equivalent untransformed
+	// draws, with the soft edge kept in target pixels
+	var rotate90, scale2 ebiten.GeoM
+	rotate90.Rotate(math.Pi / 2)
+	rotate90.Translate(w, 0) // (x, y) -> (w - y, x)
+	scale2.Scale(2, 2)
+	transformCases := []struct {
+		name        string
+		geoM        ebiten.GeoM
+		transformed func(r *Renderer, target *ebiten.Image)
+		direct      func(r *Renderer, target *ebiten.Image)
+	}{
+		{"rotated DrawArea", rotate90,
+			func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, 10, 8, 30, 20, -6) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, w-28, 10, 20, 30, -6) }},
+		{"rotated DrawCircle", rotate90,
+			func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, 30, 40, 12.5) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, w-40, 30, 12.5) }},
+		{"scaled DrawCircle", scale2,
+			func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, 24, 18, 10) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawCircle(target, 48, 36, 20) }},
+		{"scaled DrawArea", scale2,
+			func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, 5, 4, 20, 15, -3) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawArea(target, 10, 8, 40, 30, -6) }},
+		{"scaled DrawLine", scale2,
+			func(r *Renderer, target *ebiten.Image) { r.DrawLine(target, 4, 30, 40, 6, 2) },
+			func(r *Renderer, target *ebiten.Image) { r.DrawLine(target, 8, 60, 80, 12, 4) }},
+	}
+
+	runHeadless(t, func(fail func(format string, args ...any)) {
+		r := NewRenderer()
+		mask = r.NewCircle(14)
+		source = r.NewSimpleGradient(32, 28, red, blue, 0.5)
+
+		var translation ebiten.GeoM
+		translation.Translate(offX, offY)
+		parent := ebiten.NewImage(w+offX*2, h+offY*2)
+		sub := parent.SubImage(image.Rect(offX, offY, offX+w, offY+h)).(*ebiten.Image)
+		ref := ebiten.NewImage(w, h)
+		refPix := make([]byte, w*h*4)
+		subPix := make([]byte, w*h*4)
+		for _, c := range cases {
+			ref.Clear()
+			parent.Clear()
+			c.draw(r, ref)
+			r.SetGeoM(translation)
+			c.draw(r, parent)
+			r.ResetGeoM()
+			ref.ReadPixels(refPix)
+			sub.ReadPixels(subPix)
+			if msg := comparePixels(refPix, subPix, w, 2); msg != "" {
+				fail("%s: %s", c.name, msg)
+			} else if isTransparent(refPix) {
+				fail("%s: reference output is fully transparent", c.name)
+			}
+		}
+
+		transformed := ebiten.NewImage(w, h)
+		for _, c := range transformCases {
+			ref.Clear()
+			transformed.Clear()
+			c.direct(r, ref)
+			r.SetGeoM(c.geoM)
+			c.transformed(r, transformed)
+			r.ResetGeoM()
+			ref.ReadPixels(refPix)
+			transformed.ReadPixels(subPix)
+			if msg := comparePixels(refPix, subPix, w, 3); msg != "" {
+				fail("%s: %s", c.name, msg)
+			} else if isTransparent(refPix) {
+				fail("%s: reference output is fully transparent", c.name)
+			}
+		}
+	})
+}
@@ -63,32 +63,31 @@ func (r *Renderer) WarpArc(target, source *ebiten.Image, cx, cy, outRadius float
 		return // nothing to draw
 	}
 
-	srcBounds := source.Bounds()
-	sw, sh := rectSizeF32(srcBounds)
+	sw, sh := rectSizeF32(source.Bounds())
+	minX, minY, maxX, maxY, startRads, radsHalfDelta := warpArcRect(sw, sh, cx, cy, outRadius, rads)
+	r.setLocalRectCoords(target, minX, minY, maxX, maxY)
+
+	r.setFlatCustomVAs(outRadius, sw, float32(startRads), float32(radsHalfDelta*2.0))
+	ensureShaderWarpArcLoaded()
+	r.opts.Images[0] = source
+	r.opts.Uniforms["Center"] = [2]float32{cx, cy}
+	r.drawTrianglesShader(target, r.vertices[:], r.indices[:], shaderWarpArc)
+	r.opts.Images[0] = nil
+	clear(r.opts.Uniforms)
+}
+
+// warpArcRect returns the area drawn by [Renderer.WarpArc]() for a source of the
+// given size, along with the normalized start angle and half angle of the arc.
+func warpArcRect(sw, sh, cx, cy, outRadius float32, rads float64) (minX, minY, maxX, maxY float32, startRads, radsHalfDelta float64) {
 	inRadius := outRadius - sh
 	circumference := 2 * math.Pi * outRadius
-	radsHalfDelta := min(float64(sw/circumference)*math.Pi, math.Pi)
-	startRads := normURads(rads - radsHalfDelta)
-	var minX, minY, maxX, maxY float32
+	radsHalfDelta = min(float64(sw/circumference)*math.Pi, math.Pi)
+	startRads = normURads(rads - radsHalfDelta)
 	if radsHalfDelta >= math.Pi {
 		minX, minY = cx-outRadius, cy-outRadius
 		maxX, maxY = cx+outRadius, cy+outRadius
 	} else {
 		minX, minY, maxX, maxY = ringSectorBounds(cx, cy, inRadius, outRadius, startRads, normURads(rads+radsHalfDelta))
 	}
-
-	minX, minY, maxX, maxY = minX-1.0, minY-1.0, maxX+1.0, maxY+1.0
-	dstOX, dstOY := rectOriginF32(target.Bounds())
-	r.setDstRectCoords(dstOX+minX, dstOY+minY, dstOX+maxX, dstOY+maxY)
-
-	sox, soy, sfx, sfy := rectPointsF32(srcBounds)
-	r.setSrcRectCoords(sox, soy, sfx, sfy)
-
-	r.setFlatCustomVAs(outRadius, sw, float32(startRads), float32(radsHalfDelta*2.0))
-	ensureShaderWarpArcLoaded()
-	r.opts.Images[0] = source
-	r.opts.Uniforms["Center"] = [2]float32{cx, cy}
-	target.DrawTrianglesShader(r.vertices[:], r.indices[:], shaderWarpArc, &r.opts)
-	r.opts.Images[0] = nil
-	clear(r.opts.Uniforms)
+	return minX - 1.0, minY - 1.0, maxX + 1.0, maxY + 1.0, startRads, radsHalfDelta
 }
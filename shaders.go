@@ -18,9 +18,18 @@ var shaderRectSrc []byte
 //go:embed shaders/stroke_rect.kage
 var shaderStrokeRectSrc []byte
 
+//go:embed shaders/batch_uber.kage
+var shaderBatchUberSrc []byte
+
 //go:embed shaders/line.kage
 var shaderLineSrc []byte
 
+//go:embed shaders/tapered_line.kage
+var shaderTaperedLineSrc []byte
+
+//go:embed shaders/ribbon.kage
+var shaderRibbonSrc []byte
+
 //go:embed shaders/circle.kage
 var shaderCircleSrc []byte
 
@@ -54,6 +63,9 @@ var shaderHexagonSrc []byte
 //go:embed shaders/quad.kage
 var shaderQuadSrc []byte
 
+//go:embed shaders/metaballs.kage
+var shaderMetaballsSrc []byte
+
 //go:embed shaders/alpha_mask_circ.kage
 var shaderAlphaMaskCircSrc []byte
 
@@ -217,7 +229,10 @@ var shaderDefault *ebiten.Shader
 var shaderBilinear *ebiten.Shader
 var shaderRect *ebiten.Shader
 var shaderStrokeRect *ebiten.Shader
+var shaderBatchUber *ebiten.Shader
 var shaderLine *ebiten.Shader
+var shaderTaperedLine *ebiten.Shader
+var shaderRibbon *ebiten.Shader
 var shaderCircle *ebiten.Shader
 var shaderStrokeCircle *ebiten.Shader
 var shaderRing *ebiten.Shader
@@ -229,6 +244,7 @@ var shaderEllipse *ebiten.Shader
 var shaderTriangle *ebiten.Shader
 var shaderHexagon *ebiten.Shader
 var shaderQuad *ebiten.Shader
+var shaderMetaballs *ebiten.Shader
 var shaderAlphaMaskCirc *ebiten.Shader
 var shaderMask *ebiten.Shader
 var shaderMaskAt *ebiten.Shader
@@ -316,12 +332,30 @@ func ensureShaderStrokeRectLoaded() {
 	}
 }
 
+func ensureShaderBatchUberLoaded() {
+	if shaderBatchUber == nil {
+		shaderBatchUber = mustCompile(shaderBatchUberSrc)
+	}
+}
+
 func ensureShaderLineLoaded() {
 	if shaderLine == nil {
 		shaderLine = mustCompile(shaderLineSrc)
 	}
 }
 
+func ensureShaderTaperedLineLoaded() {
+	if shaderTaperedLine == nil {
+		shaderTaperedLine = mustCompile(shaderTaperedLineSrc)
+	}
+}
+
+func ensureShaderRibbonLoaded() {
+	if shaderRibbon == nil {
+		shaderRibbon = mustCompile(shaderRibbonSrc)
+	}
+}
+
 func ensureShaderCircleLoaded() {
 	if shaderCircle == nil {
 		shaderCircle = mustCompile(shaderCircleSrc)
@@ -388,6 +422,12 @@ func ensureShaderQuadLoaded() {
 	}
 }
 
+func ensureShaderMetaballsLoaded() {
+	if shaderMetaballs == nil {
+		shaderMetaballs = mustCompile(shaderMetaballsSrc)
+	}
+}
+
 func ensureShaderAlphaMaskCircLoaded() {
 	if shaderAlphaMaskCirc == nil {
 		shaderAlphaMaskCirc = mustCompile(shaderAlphaMaskCircSrc)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"math"
+	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -111,3 +112,57 @@ func (app *TestApp) Update() error {
 func (app *TestApp) Draw(canvas *ebiten.Image) {
 	app.drawer(canvas, app.TestAppCtx)
 }
+
+// comparePixels returns a non-empty description of the first
+// pixel where a and b differ by more than the given tolerance.
+func comparePixels(a, b []byte, width int, tolerance int) string {
+	for i := range a {
+		diff := int(a[i]) - int(b[i])
+		if diff > tolerance || diff < -tolerance {
+			x, y := (i/4)%width, (i/4)/width
+			return fmt.Sprintf("pixel (%d, %d) mismatch: %v vs %v", x, y, a[i-i%4:i-i%4+4], b[i-i%4:i-i%4+4])
+		}
+	}
+	return ""
+}
+
+func isTransparent(pix []byte) bool {
+	for i := 3; i < len(pix); i += 4 {
+		if pix[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// testGameThread runs fn once from within the game loop,
+// where pixels can be read back, and then terminates.
+type testGameThread struct {
+	fn func()
+}
+
+func (t *testGameThread) Draw(*ebiten.Image) {}
+func (t *testGameThread) Layout(w, h int) (int, int) {
+	return w, h
+}
+func (t *testGameThread) Update() error {
+	t.fn()
+	return ebiten.Termination
+}
+
+// runHeadless runs fn once from within the game loop, where pixels can be
+// read back, and reports the failures collected through fail as test errors.
+func runHeadless(t *testing.T, fn func(fail func(format string, args ...any))) {
+	t.Helper()
+	var failures []string
+	fail := func(format string, args ...any) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+	err := ebiten.RunGame(&testGameThread{fn: func() { fn(fail) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, failure := range failures {
+		t.Error(failure)
+	}
+}